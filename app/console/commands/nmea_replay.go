@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/services"
+)
+
+type NMEAReplay struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *NMEAReplay) Signature() string {
+	return "nmea:replay"
+}
+
+// Description The console command description.
+func (receiver *NMEAReplay) Description() string {
+	return "Replay a recorded NMEA log through the ingest pipeline"
+}
+
+// Extend The console command extend.
+func (receiver *NMEAReplay) Extend() command.Extend {
+	return command.Extend{
+		Category: "nmea",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Log to replay: a raw archive file (.nmea.gz), or plain sentences optionally prefixed with CALLSIGN,",
+				Required: true,
+			},
+			&command.StringFlag{
+				Name:    "call-sign",
+				Aliases: []string{"c"},
+				Usage:   "Vessel to replay as, required unless every line has a CALLSIGN, prefix",
+			},
+			&command.StringFlag{
+				Name:    "speed",
+				Aliases: []string{"s"},
+				Usage:   "Playback speed such as 1x or 10x, or max to send without waiting",
+				Value:   "1x",
+			},
+			&command.StringFlag{
+				Name:  "target",
+				Usage: "host:port of a running navigation listener; without it the log is processed in this process against the configured database",
+			},
+			&command.BoolFlag{
+				Name:  "force",
+				Usage: "Process the log in this process even though it writes into the configured database; point DB_DATABASE at a scratch database first",
+			},
+			&command.StringFlag{
+				Name:  "token",
+				Usage: "Device secret for the handshake when the target requires authentication",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *NMEAReplay) Handle(ctx console.Context) error {
+	speed, err := parseReplaySpeed(ctx.Option("speed"))
+	if err != nil {
+		ctx.Error(err.Error())
+		return nil
+	}
+
+	log, err := services.OpenReplayLog(ctx.Option("file"))
+	if err != nil {
+		ctx.Error(fmt.Sprintf("Failed to open log: %v", err))
+		return nil
+	}
+	defer log.Close()
+
+	var sink services.ReplaySink
+	if target := ctx.Option("target"); target != "" {
+		if sink, err = services.NewTCPReplaySink(target, ctx.Option("token")); err != nil {
+			ctx.Error(fmt.Sprintf("Failed to connect to %s: %v", target, err))
+			return nil
+		}
+		ctx.Info(fmt.Sprintf("Replaying into the listener at %s", target))
+	} else {
+		connection := facades.Config().GetString("database.default")
+		database := facades.Config().GetString("database.connections." + connection + ".database")
+		if !ctx.OptionBool("force") {
+			ctx.Error(fmt.Sprintf("Refusing to replay into database %q of connection %s without --force. "+
+				"Run with DB_DATABASE set to a scratch database and --force, or use --target to replay into a running listener.", database, connection))
+			return nil
+		}
+		sink = services.NewLocalReplaySink()
+		ctx.Info(fmt.Sprintf("Replaying in process into database %q of connection %s", database, connection))
+	}
+	defer sink.Close()
+
+	// Stop cleanly on Ctrl+C
+	replayCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stats, err := services.Replay(replayCtx, log, sink, ctx.Option("call-sign"), speed)
+	ctx.Info(fmt.Sprintf("%d lines, %d sent, %d rejected in %s", stats.Lines, stats.Sent, stats.Rejected, stats.Duration.Round(time.Millisecond)))
+	if err != nil && replayCtx.Err() == nil {
+		ctx.Error(fmt.Sprintf("Replay stopped: %v", err))
+	}
+
+	return nil
+}
+
+// parseReplaySpeed accepts "10x", "10", "0.5x" or "max"
+func parseReplaySpeed(value string) (float64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "max" {
+		return 0, nil
+	}
+
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q, use e.g. 1x, 10x or max", value)
+	}
+	return speed, nil
+}
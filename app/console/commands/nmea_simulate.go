@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/helpers/secret"
+	"goravel/app/helpers/simulator"
+	"goravel/app/models"
+)
+
+type NMEASimulate struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *NMEASimulate) Signature() string {
+	return "nmea:simulate"
+}
+
+// Description The console command description.
+func (receiver *NMEASimulate) Description() string {
+	return "Simulate vessels and sensors connecting to the TCP listeners"
+}
+
+// Extend The console command extend.
+func (receiver *NMEASimulate) Extend() command.Extend {
+	return command.Extend{
+		Category: "nmea",
+		Flags: []command.Flag{
+			&command.IntFlag{
+				Name:    "vessels",
+				Aliases: []string{"n"},
+				Usage:   "Number of synthetic vessels",
+				Value:   5,
+			},
+			&command.StringFlag{
+				Name:  "prefix",
+				Usage: "Call sign prefix, vessels are named PREFIX001, PREFIX002...",
+				Value: "SIM",
+			},
+			&command.IntFlag{
+				Name:  "sensors",
+				Usage: "Number of synthetic sensors",
+				Value: 0,
+			},
+			&command.IntFlag{
+				Name:  "sensor-start",
+				Usage: "ID of the first synthetic sensor",
+				Value: 9001,
+			},
+			&command.StringFlag{
+				Name:  "center",
+				Usage: "lat,lon the vessels start around and wander near",
+				Value: "-1.2654,116.8312",
+			},
+			&command.StringFlag{
+				Name:  "route",
+				Usage: "Waypoints as lat,lon;lat,lon;... for the vessels to follow in a loop instead of wandering",
+			},
+			&command.StringFlag{
+				Name:  "interval",
+				Usage: "Time between updates of each device",
+				Value: "1s",
+			},
+			&command.StringFlag{
+				Name:  "duration",
+				Usage: "Stop after this long, runs until Ctrl+C when empty",
+			},
+			&command.StringFlag{
+				Name:  "host",
+				Usage: "Host of the listeners, defaults to the configured host",
+			},
+			&command.BoolFlag{
+				Name:  "register",
+				Usage: "Create missing synthetic vessels and sensors and issue the vessels new device secrets",
+			},
+			&command.StringFlag{
+				Name:  "token",
+				Usage: "Device secret or IP token the vessels send in the CALLSIGN,TOKEN handshake",
+			},
+			&command.BoolFlag{
+				Name:  "tls",
+				Usage: "Connect to the listeners over TLS",
+			},
+			&command.BoolFlag{
+				Name:  "insecure",
+				Usage: "With --tls, accept any server certificate, e.g. a self-signed one",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *NMEASimulate) Handle(ctx console.Context) error {
+	center, err := parseWaypoint(ctx.Option("center"))
+	if err != nil {
+		ctx.Error(err.Error())
+		return nil
+	}
+	var route []simulator.Waypoint
+	if value := ctx.Option("route"); value != "" {
+		for _, point := range strings.Split(value, ";") {
+			waypoint, err := parseWaypoint(point)
+			if err != nil {
+				ctx.Error(err.Error())
+				return nil
+			}
+			route = append(route, waypoint)
+		}
+	}
+	interval, err := time.ParseDuration(ctx.Option("interval"))
+	if err != nil || interval <= 0 {
+		ctx.Error("Invalid interval, use e.g. 1s or 500ms")
+		return nil
+	}
+
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if value := ctx.Option("duration"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			ctx.Error("Invalid duration, use e.g. 10m")
+			return nil
+		}
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, duration)
+		defer cancel()
+	}
+
+	register := ctx.OptionBool("register")
+	token := ctx.Option("token")
+	if register && token != "" {
+		ctx.Error("Use either --register or --token, --register issues each vessel its own secret")
+		return nil
+	}
+	var tlsConfig *tls.Config
+	if ctx.OptionBool("tls") {
+		tlsConfig = &tls.Config{InsecureSkipVerify: ctx.OptionBool("insecure")}
+	} else if ctx.OptionBool("insecure") {
+		ctx.Error("--insecure only applies with --tls")
+		return nil
+	}
+	host := ctx.Option("host")
+	var wg sync.WaitGroup
+
+	navigationAddress := listenerAddress(host, "navigation")
+	for i := 0; i < ctx.OptionInt("vessels"); i++ {
+		callSign := fmt.Sprintf("%s%03d", ctx.Option("prefix"), i+1)
+		handshake := callSign
+		if token != "" {
+			handshake = callSign + "," + token
+		}
+		if register {
+			token, err := registerSimulatedVessel(callSign)
+			if err != nil {
+				ctx.Error(fmt.Sprintf("Failed to register %s: %v", callSign, err))
+				return nil
+			}
+			handshake = callSign + "," + token
+		}
+
+		vessel := simulator.NewVessel(callSign, startPosition(center, route, i), route, int64(i+1))
+		last := time.Now()
+		next := func(now time.Time) []string {
+			vessel.Step(now.Sub(last))
+			last = now
+			return vessel.Sentences(now)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			simulator.Feed(runCtx, navigationAddress, tlsConfig, handshake, interval, next, func(err error) {
+				facades.Log().Warning(fmt.Sprintf("Simulated vessel %s: %v", callSign, err))
+			})
+		}()
+	}
+
+	sensorAddress := listenerAddress(host, "sensor")
+	sensorTypes := models.GetSupportedTypes()
+	sort.Strings(sensorTypes)
+	for i := 0; i < ctx.OptionInt("sensors"); i++ {
+		id := strconv.Itoa(ctx.OptionInt("sensor-start") + i)
+		types := []string{sensorTypes[i%len(sensorTypes)]}
+		if register {
+			if err := registerSimulatedSensor(id, types, startPosition(center, nil, i)); err != nil {
+				ctx.Error(fmt.Sprintf("Failed to register sensor %s: %v", id, err))
+				return nil
+			}
+		}
+
+		sensor := simulator.NewSensor(id, types, int64(i+1))
+		next := func(now time.Time) []string {
+			return []string{sensor.Message(now)}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			simulator.Feed(runCtx, sensorAddress, tlsConfig, "", interval, next, func(err error) {
+				facades.Log().Warning(fmt.Sprintf("Simulated sensor %s: %v", id, err))
+			})
+		}()
+	}
+
+	ctx.Info(fmt.Sprintf("Simulating %d vessels on %s and %d sensors on %s, Ctrl+C to stop",
+		ctx.OptionInt("vessels"), navigationAddress, ctx.OptionInt("sensors"), sensorAddress))
+	wg.Wait()
+	ctx.Info("Simulation stopped")
+
+	return nil
+}
+
+// listenerAddress returns the address of a configured listener, reaching a
+// wildcard listen address over loopback
+func listenerAddress(host string, section string) string {
+	if host == "" {
+		host = facades.Config().GetString("tcp." + section + ".host")
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, facades.Config().GetString("tcp."+section+".port"))
+}
+
+// startPosition spreads devices over the route or within about 3 NM of center
+func startPosition(center simulator.Waypoint, route []simulator.Waypoint, index int) simulator.Waypoint {
+	if len(route) > 0 {
+		return route[index%len(route)]
+	}
+	r := rand.New(rand.NewSource(int64(index + 1)))
+	return simulator.Waypoint{
+		Latitude:  center.Latitude + (r.Float64()*2-1)*0.05,
+		Longitude: center.Longitude + (r.Float64()*2-1)*0.05,
+	}
+}
+
+func parseWaypoint(value string) (simulator.Waypoint, error) {
+	lat, lon, found := strings.Cut(value, ",")
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if !found || latErr != nil || lonErr != nil {
+		return simulator.Waypoint{}, fmt.Errorf("invalid position %q, use lat,lon", value)
+	}
+	return simulator.Waypoint{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// registerSimulatedVessel creates the vessel if it does not exist and gives it
+// a new device secret, which is returned for the handshake
+func registerSimulatedVessel(callSign string) (string, error) {
+	var count int64
+	if err := facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).Count(&count); err != nil {
+		return "", err
+	}
+	if count == 0 {
+		kapal := models.Kapal{
+			CallSign:                    callSign,
+			Flag:                        "SIM",
+			Kelas:                       "Simulated",
+			Builder:                     "Simulator",
+			YearBuilt:                   uint(time.Now().Year()),
+			WidthM:                      10,
+			LengthM:                     40,
+			BowToStern:                  40,
+			PortToStarboard:             10,
+			HistoryPerSecond:            5,
+			MinimumKnotPerLiterGasoline: 1,
+			MaximumKnotPerLiterGasoline: 2,
+			RecordStatus:                true,
+			CreatedAt:                   time.Now(),
+			UpdatedAt:                   time.Now(),
+		}
+		if err := facades.Orm().Query().Create(&kapal); err != nil {
+			return "", err
+		}
+	}
+
+	deviceSecret, hash, err := secret.Generate()
+	if err != nil {
+		return "", err
+	}
+	_, err = facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).Update(map[string]any{
+		"device_secret_hash": hash,
+		"secret_rotated_at":  time.Now(),
+	})
+	return deviceSecret, err
+}
+
+// registerSimulatedSensor creates the sensor if it does not exist
+func registerSimulatedSensor(id string, types []string, position simulator.Waypoint) error {
+	var count int64
+	if err := facades.Orm().Query().Model(&models.Sensor{}).Where("id", id).Count(&count); err != nil || count > 0 {
+		return err
+	}
+
+	return facades.Orm().Query().Create(&models.Sensor{
+		ID:        id,
+		Types:     types,
+		Latitude:  strconv.FormatFloat(position.Latitude, 'f', 6, 64),
+		Longitude: strconv.FormatFloat(position.Longitude, 'f', 6, 64),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+}
@@ -3,6 +3,8 @@ package console
 import (
 	"github.com/goravel/framework/contracts/console"
 	"github.com/goravel/framework/contracts/schedule"
+
+	"goravel/app/console/commands"
 )
 
 type Kernel struct {
@@ -13,5 +15,8 @@ func (kernel Kernel) Schedule() []schedule.Event {
 }
 
 func (kernel Kernel) Commands() []console.Command {
-	return []console.Command{}
+	return []console.Command{
+		&commands.NMEAReplay{},
+		&commands.NMEASimulate{},
+	}
 }
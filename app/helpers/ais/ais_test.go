@@ -0,0 +1,74 @@
+package ais
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"goravel/app/helpers/nmea"
+)
+
+func TestDecodeClassAPosition(t *testing.T) {
+	message, err := Decode("177KQJ5000G?tO`K>RA1wUbN0TKH", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, ok := message.(PositionReport)
+	if !ok {
+		t.Fatalf("got %T, want PositionReport", message)
+	}
+	if report.MMSI != 477553000 || report.NavigationStatus != 5 {
+		t.Errorf("mmsi = %d, status = %d", report.MMSI, report.NavigationStatus)
+	}
+	if math.Abs(report.Latitude-47.582833) > 1e-5 || math.Abs(report.Longitude+122.345833) > 1e-5 {
+		t.Errorf("position = %v,%v", report.Latitude, report.Longitude)
+	}
+	if report.CourseOverGround != 51 || report.TrueHeading != 181 {
+		t.Errorf("course = %v, heading = %d", report.CourseOverGround, report.TrueHeading)
+	}
+}
+
+func TestAssembleStaticVoyageData(t *testing.T) {
+	assembler := NewAssembler()
+	var payload string
+	var fillBits int
+	var complete bool
+
+	for _, raw := range []string{
+		"!AIVDM,2,1,1,A,55?MbV02;H;s<HtKR20EHE:0@T4@Dn2222222216L961O5Gf0NSQEp6ClRp8,0*1C",
+		"!AIVDM,2,2,1,A,88888888880,2*25",
+	} {
+		sentence, err := nmea.Parse(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		payload, fillBits, complete = assembler.Add("test", sentence.(nmea.VDM))
+	}
+	if !complete {
+		t.Fatal("message not complete after last fragment")
+	}
+
+	message, err := Decode(payload, fillBits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	static := message.(StaticVoyageData)
+	if static.MMSI != 351759000 || static.IMO != 9134270 || static.CallSign != "3FOF8" {
+		t.Errorf("static = %+v", static)
+	}
+	if static.Name != "EVER DIADEM" || static.Destination != "NEW YORK" || static.Draught != 12.2 {
+		t.Errorf("static = %+v", static)
+	}
+}
+
+func TestDecodeRejectsUnsupported(t *testing.T) {
+	// Type 4 base station report
+	if _, err := Decode("403OviQuMGCqWrRO9>E6fE700@GO", 0); !errors.Is(err, ErrUnsupportedMessage) {
+		t.Fatalf("error = %v, want %v", err, ErrUnsupportedMessage)
+	}
+	if _, err := Decode("1{", 0); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidPayload)
+	}
+}
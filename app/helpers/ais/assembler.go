@@ -0,0 +1,80 @@
+package ais
+
+import (
+	"fmt"
+	"goravel/app/helpers/nmea"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fragmentTimeout is how long a partial multi-fragment message is kept
+const fragmentTimeout = 10 * time.Second
+
+// Assembler joins multi-fragment AIVDM/AIVDO sentences back into one payload
+type Assembler struct {
+	mutex   sync.Mutex
+	pending map[string]*pendingMessage
+}
+
+type pendingMessage struct {
+	fragments []string
+	received  int
+	fillBits  int
+	started   time.Time
+}
+
+// NewAssembler creates an empty fragment assembler
+func NewAssembler() *Assembler {
+	return &Assembler{
+		pending: make(map[string]*pendingMessage),
+	}
+}
+
+// Add stores a fragment received from source. Once the last fragment of a
+// message is in, it returns the joined payload and its fill bits.
+func (a *Assembler) Add(source string, vdm nmea.VDM) (payload string, fillBits int, complete bool) {
+	if vdm.FragmentCount == 1 {
+		return vdm.Payload, vdm.FillBits, true
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.expire()
+
+	key := fmt.Sprintf("%s|%s|%s|%s", source, vdm.Prefix(), vdm.MessageID, vdm.Channel)
+	message, exists := a.pending[key]
+	if !exists || vdm.FragmentNumber == 1 || len(message.fragments) != vdm.FragmentCount {
+		message = &pendingMessage{
+			fragments: make([]string, vdm.FragmentCount),
+			started:   time.Now(),
+		}
+		a.pending[key] = message
+	}
+
+	if message.fragments[vdm.FragmentNumber-1] == "" {
+		message.received++
+	}
+	message.fragments[vdm.FragmentNumber-1] = vdm.Payload
+	if vdm.FragmentNumber == vdm.FragmentCount {
+		message.fillBits = vdm.FillBits
+	}
+
+	if message.received < vdm.FragmentCount {
+		return "", 0, false
+	}
+
+	delete(a.pending, key)
+	return strings.Join(message.fragments, ""), message.fillBits, true
+}
+
+// expire drops partial messages whose remaining fragments never arrived.
+// The caller must hold the mutex.
+func (a *Assembler) expire() {
+	for key, message := range a.pending {
+		if time.Since(message.started) > fragmentTimeout {
+			delete(a.pending, key)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package ais
+
+import "fmt"
+
+// Values the AIS standard uses for "not available"
+const (
+	lonNotAvailable     = 181 * 600000
+	latNotAvailable     = 91 * 600000
+	speedNotAvailable   = 1023
+	courseNotAvailable  = 3600
+	headingNotAvailable = 511
+)
+
+// Message is implemented by every decoded AIS message
+type Message interface {
+	Type() int
+	UserID() uint32
+}
+
+// Header holds the fields shared by every message
+type Header struct {
+	MessageType int
+	Repeat      int
+	MMSI        uint32
+}
+
+// Type returns the AIS message type
+func (h Header) Type() int {
+	return h.MessageType
+}
+
+// UserID returns the MMSI of the transmitting station
+func (h Header) UserID() uint32 {
+	return h.MMSI
+}
+
+// Dimensions of the ship relative to its position reference point, in metres
+type Dimensions struct {
+	ToBow       int
+	ToStern     int
+	ToPort      int
+	ToStarboard int
+}
+
+// PositionReport is a Class A (types 1, 2, 3) or Class B (types 18, 19) position report
+type PositionReport struct {
+	Header
+	NavigationStatus int // Class A only; 15 is "not defined"
+	RateOfTurn       int // Raw ROT indicator, Class A only
+	SpeedOverGround  float64
+	HasSpeed         bool
+	PositionAccuracy bool
+	Longitude        float64
+	Latitude         float64
+	HasPosition      bool
+	CourseOverGround float64
+	HasCourse        bool
+	TrueHeading      int
+	HasHeading       bool
+	Timestamp        int // UTC second of the report
+	ClassB           bool
+}
+
+// StaticVoyageData is a Class A static and voyage related report (type 5)
+type StaticVoyageData struct {
+	Header
+	IMO         uint32
+	CallSign    string
+	Name        string
+	ShipType    int
+	Dimensions  Dimensions
+	Draught     float64 // Metres
+	Destination string
+}
+
+// ExtendedClassBReport is a Class B extended position report (type 19)
+type ExtendedClassBReport struct {
+	PositionReport
+	Name       string
+	ShipType   int
+	Dimensions Dimensions
+}
+
+// StaticDataReport is one part of a Class B static data report (type 24)
+type StaticDataReport struct {
+	Header
+	PartNumber int // 0 carries the name, 1 the rest
+	Name       string
+	ShipType   int
+	VendorID   string
+	CallSign   string
+	Dimensions Dimensions
+}
+
+// Decode decodes a complete six-bit payload
+func Decode(payload string, fillBits int) (Message, error) {
+	r, err := newBitReader(payload, fillBits)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() < 38 {
+		return nil, fmt.Errorf("%w: %d bits", ErrPayloadTooShort, r.Len())
+	}
+
+	header := Header{
+		MessageType: int(r.Uint(0, 6)),
+		Repeat:      int(r.Uint(6, 2)),
+		MMSI:        uint32(r.Uint(8, 30)),
+	}
+
+	switch header.MessageType {
+	case 1, 2, 3:
+		return decodeClassAPosition(r, header)
+	case 5:
+		return decodeStaticVoyageData(r, header)
+	case 18:
+		return decodeClassBPosition(r, header)
+	case 19:
+		return decodeExtendedClassB(r, header)
+	case 24:
+		return decodeStaticDataReport(r, header)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedMessage, header.MessageType)
+	}
+}
+
+func decodeClassAPosition(r *bitReader, header Header) (Message, error) {
+	if r.Len() < 149 {
+		return nil, fmt.Errorf("%w: type %d needs 149 bits, got %d", ErrPayloadTooShort, header.MessageType, r.Len())
+	}
+
+	report := PositionReport{
+		Header:           header,
+		NavigationStatus: int(r.Uint(38, 4)),
+		RateOfTurn:       int(r.Int(42, 8)),
+		PositionAccuracy: r.Bool(60),
+		Timestamp:        int(r.Uint(137, 6)),
+	}
+	readMotion(r, &report, 50, 61, 89, 116, 128)
+	return report, nil
+}
+
+func decodeClassBPosition(r *bitReader, header Header) (Message, error) {
+	if r.Len() < 139 {
+		return nil, fmt.Errorf("%w: type 18 needs 139 bits, got %d", ErrPayloadTooShort, r.Len())
+	}
+
+	report := PositionReport{
+		Header:           header,
+		NavigationStatus: 15,
+		PositionAccuracy: r.Bool(56),
+		Timestamp:        int(r.Uint(133, 6)),
+		ClassB:           true,
+	}
+	readMotion(r, &report, 46, 57, 85, 112, 124)
+	return report, nil
+}
+
+func decodeExtendedClassB(r *bitReader, header Header) (Message, error) {
+	if r.Len() < 301 {
+		return nil, fmt.Errorf("%w: type 19 needs 301 bits, got %d", ErrPayloadTooShort, r.Len())
+	}
+
+	position, _ := decodeClassBPosition(r, header)
+	return ExtendedClassBReport{
+		PositionReport: position.(PositionReport),
+		Name:           r.Text(143, 120),
+		ShipType:       int(r.Uint(263, 8)),
+		Dimensions:     readDimensions(r, 271),
+	}, nil
+}
+
+func decodeStaticVoyageData(r *bitReader, header Header) (Message, error) {
+	if r.Len() < 422 {
+		return nil, fmt.Errorf("%w: type 5 needs 422 bits, got %d", ErrPayloadTooShort, r.Len())
+	}
+
+	return StaticVoyageData{
+		Header:      header,
+		IMO:         uint32(r.Uint(40, 30)),
+		CallSign:    r.Text(70, 42),
+		Name:        r.Text(112, 120),
+		ShipType:    int(r.Uint(232, 8)),
+		Dimensions:  readDimensions(r, 240),
+		Draught:     float64(r.Uint(294, 8)) / 10,
+		Destination: r.Text(302, 120),
+	}, nil
+}
+
+func decodeStaticDataReport(r *bitReader, header Header) (Message, error) {
+	if r.Len() < 160 {
+		return nil, fmt.Errorf("%w: type 24 needs 160 bits, got %d", ErrPayloadTooShort, r.Len())
+	}
+
+	report := StaticDataReport{
+		Header:     header,
+		PartNumber: int(r.Uint(38, 2)),
+	}
+
+	switch report.PartNumber {
+	case 0:
+		report.Name = r.Text(40, 120)
+	case 1:
+		if r.Len() < 162 {
+			return nil, fmt.Errorf("%w: type 24 part B needs 162 bits, got %d", ErrPayloadTooShort, r.Len())
+		}
+		report.ShipType = int(r.Uint(40, 8))
+		report.VendorID = r.Text(48, 18)
+		report.CallSign = r.Text(90, 42)
+		report.Dimensions = readDimensions(r, 132)
+	default:
+		return nil, fmt.Errorf("%w: type 24 part %d", ErrUnsupportedMessage, report.PartNumber)
+	}
+	return report, nil
+}
+
+// readMotion fills in speed, position, course and heading, which share the
+// same encoding across Class A and Class B reports
+func readMotion(r *bitReader, report *PositionReport, speedAt, lonAt, latAt, courseAt, headingAt int) {
+	if speed := r.Uint(speedAt, 10); speed != speedNotAvailable {
+		report.SpeedOverGround, report.HasSpeed = float64(speed)/10, true
+	}
+
+	lon, lat := r.Int(lonAt, 28), r.Int(latAt, 27)
+	if lon != lonNotAvailable && lat != latNotAvailable &&
+		lon >= -180*600000 && lon <= 180*600000 && lat >= -90*600000 && lat <= 90*600000 {
+		report.Longitude = float64(lon) / 600000
+		report.Latitude = float64(lat) / 600000
+		report.HasPosition = true
+	}
+
+	if course := r.Uint(courseAt, 12); course < courseNotAvailable {
+		report.CourseOverGround, report.HasCourse = float64(course)/10, true
+	}
+	if heading := r.Uint(headingAt, 9); heading != headingNotAvailable && heading < 360 {
+		report.TrueHeading, report.HasHeading = int(heading), true
+	}
+}
+
+func readDimensions(r *bitReader, start int) Dimensions {
+	return Dimensions{
+		ToBow:       int(r.Uint(start, 9)),
+		ToStern:     int(r.Uint(start+9, 9)),
+		ToPort:      int(r.Uint(start+18, 6)),
+		ToStarboard: int(r.Uint(start+24, 6)),
+	}
+}
@@ -0,0 +1,92 @@
+// Package ais decodes AIS messages carried in AIVDM/AIVDO sentences.
+package ais
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidPayload     = errors.New("invalid payload character")
+	ErrPayloadTooShort    = errors.New("payload too short")
+	ErrUnsupportedMessage = errors.New("unsupported message type")
+)
+
+// bitReader reads big-endian bit fields out of a decoded six-bit payload
+type bitReader struct {
+	bits []byte // one entry per bit, 0 or 1
+}
+
+// newBitReader de-armours the payload and drops the trailing fill bits
+func newBitReader(payload string, fillBits int) (*bitReader, error) {
+	bits := make([]byte, 0, len(payload)*6)
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c < '0' || c > 'w' || (c > 'W' && c < '`') {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPayload, c)
+		}
+
+		value := c - '0'
+		if value > 40 {
+			value -= 8
+		}
+		for shift := 5; shift >= 0; shift-- {
+			bits = append(bits, (value>>shift)&1)
+		}
+	}
+
+	if fillBits > 0 && fillBits <= len(bits) {
+		bits = bits[:len(bits)-fillBits]
+	}
+	return &bitReader{bits: bits}, nil
+}
+
+// Len returns the number of usable bits
+func (r *bitReader) Len() int {
+	return len(r.bits)
+}
+
+// Uint reads an unsigned field; bits past the end of the payload read as zero
+func (r *bitReader) Uint(start, length int) uint64 {
+	var value uint64
+	for i := start; i < start+length; i++ {
+		value <<= 1
+		if i < len(r.bits) {
+			value |= uint64(r.bits[i])
+		}
+	}
+	return value
+}
+
+// Int reads a two's complement signed field
+func (r *bitReader) Int(start, length int) int64 {
+	value := r.Uint(start, length)
+	if value&(1<<(length-1)) != 0 {
+		return int64(value) - (1 << length)
+	}
+	return int64(value)
+}
+
+// Bool reads a single bit
+func (r *bitReader) Bool(start int) bool {
+	return r.Uint(start, 1) == 1
+}
+
+// Text reads six-bit ASCII, trimming the '@' padding and trailing spaces
+func (r *bitReader) Text(start, length int) string {
+	var sb strings.Builder
+	for i := start; i+6 <= start+length && i+6 <= len(r.bits); i += 6 {
+		c := byte(r.Uint(i, 6))
+		if c < 32 {
+			c += 64
+		}
+		sb.WriteByte(c)
+	}
+
+	text := sb.String()
+	if idx := strings.IndexByte(text, '@'); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.TrimRight(text, " ")
+}
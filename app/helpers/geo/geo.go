@@ -0,0 +1,182 @@
+// Package geo holds the spherical geometry used to judge vessel positions:
+// great circle distance and point in polygon tests against GeoJSON land
+// masks.
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+const (
+	EarthRadiusMetres     = 6371000.0 // Mean radius used by the haversine formula
+	MetresPerNauticalMile = 1852.0
+)
+
+// DistanceMetres is the haversine distance between two positions in decimal degrees
+func DistanceMetres(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi, dLambda := (lat2-lat1)*math.Pi/180, (lon2-lon1)*math.Pi/180
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMetres * math.Asin(math.Sqrt(a))
+}
+
+// Destination returns the position reached from a start position after
+// travelling distance metres along a true bearing in degrees
+func Destination(lat, lon, bearing, distance float64) (float64, float64) {
+	phi1, lambda1 := lat*math.Pi/180, lon*math.Pi/180
+	theta, delta := bearing*math.Pi/180, distance/EarthRadiusMetres
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1), math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+
+	// Keep the longitude within -180..180
+	lon2 := math.Mod(lambda2*180/math.Pi+540, 360) - 180
+	return phi2 * 180 / math.Pi, lon2
+}
+
+// Point is a longitude, latitude pair in GeoJSON order
+type Point [2]float64
+
+// Polygon is an outer ring followed by any holes
+type Polygon struct {
+	Rings [][]Point
+	// Bounding box of the outer ring, checked before the rings
+	minLon, minLat, maxLon, maxLat float64
+}
+
+// NewPolygon creates a polygon from its rings, outer ring first
+func NewPolygon(rings [][]Point) Polygon {
+	polygon := Polygon{Rings: rings, minLon: math.Inf(1), minLat: math.Inf(1), maxLon: math.Inf(-1), maxLat: math.Inf(-1)}
+	if len(rings) > 0 {
+		for _, point := range rings[0] {
+			polygon.minLon, polygon.maxLon = math.Min(polygon.minLon, point[0]), math.Max(polygon.maxLon, point[0])
+			polygon.minLat, polygon.maxLat = math.Min(polygon.minLat, point[1]), math.Max(polygon.maxLat, point[1])
+		}
+	}
+	return polygon
+}
+
+// Contains reports whether a position lies inside the outer ring and outside
+// every hole
+func (p Polygon) Contains(lat, lon float64) bool {
+	if len(p.Rings) == 0 || lon < p.minLon || lon > p.maxLon || lat < p.minLat || lat > p.maxLat {
+		return false
+	}
+	if !ringContains(p.Rings[0], lat, lon) {
+		return false
+	}
+	for _, hole := range p.Rings[1:] {
+		if ringContains(hole, lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test on a closed or open ring
+func ringContains(ring []Point, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// LandMask is a set of land polygons
+type LandMask struct {
+	Polygons []Polygon
+}
+
+// Contains reports whether a position is on land
+func (m *LandMask) Contains(lat, lon float64) bool {
+	if m == nil {
+		return false
+	}
+	for _, polygon := range m.Polygons {
+		if polygon.Contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// geoJSON covers the parts of FeatureCollection, Feature and geometry
+// objects that carry polygons
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Features    []geoJSON       `json:"features"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ParseLandMask reads the Polygon and MultiPolygon geometries of a GeoJSON
+// document; other geometry types are ignored
+func ParseLandMask(data []byte) (*LandMask, error) {
+	var document geoJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	mask := &LandMask{}
+	if err := mask.add(document); err != nil {
+		return nil, err
+	}
+	if len(mask.Polygons) == 0 {
+		return nil, errors.New("no polygons in GeoJSON")
+	}
+	return mask, nil
+}
+
+// LoadLandMask reads a GeoJSON land mask from a file
+func LoadLandMask(path string) (*LandMask, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLandMask(data)
+}
+
+func (m *LandMask) add(object geoJSON) error {
+	switch object.Type {
+	case "FeatureCollection":
+		for _, feature := range object.Features {
+			if err := m.add(feature); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if object.Geometry != nil {
+			return m.add(*object.Geometry)
+		}
+	case "GeometryCollection":
+		for _, geometry := range object.Geometries {
+			if err := m.add(geometry); err != nil {
+				return err
+			}
+		}
+	case "Polygon":
+		var rings [][]Point
+		if err := json.Unmarshal(object.Coordinates, &rings); err != nil {
+			return fmt.Errorf("polygon coordinates: %w", err)
+		}
+		m.Polygons = append(m.Polygons, NewPolygon(rings))
+	case "MultiPolygon":
+		var polygons [][][]Point
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("multipolygon coordinates: %w", err)
+		}
+		for _, rings := range polygons {
+			m.Polygons = append(m.Polygons, NewPolygon(rings))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceMetres(t *testing.T) {
+	// One minute of latitude is one nautical mile
+	distance := DistanceMetres(-1.0, 116.8, -1.0+1.0/60, 116.8)
+	if math.Abs(distance-MetresPerNauticalMile) > 5 {
+		t.Fatalf("got %.1f m, want about %.0f m", distance, MetresPerNauticalMile)
+	}
+}
+
+func TestDestination(t *testing.T) {
+	cases := []struct {
+		name              string
+		lat, lon, bearing float64
+		distance          float64
+		wantLat, wantLon  float64
+	}{
+		{"north one mile", -1.0, 116.8, 0, MetresPerNauticalMile, -1.0 + 1.0/60, 116.8},
+		{"east across the antimeridian", 0, 179.99, 90, 2 * 1113.2, 0, -179.99},
+		{"no distance", 10, 20, 123, 0, 10, 20},
+	}
+	for _, c := range cases {
+		lat, lon := Destination(c.lat, c.lon, c.bearing, c.distance)
+		if math.Abs(lat-c.wantLat) > 1e-4 || math.Abs(lon-c.wantLon) > 1e-4 {
+			t.Errorf("%s: got %.5f, %.5f, want %.5f, %.5f", c.name, lat, lon, c.wantLat, c.wantLon)
+		}
+	}
+
+	// Going out and back again returns to the start
+	lat, lon := Destination(-1.27, 116.8, 45, 5000)
+	if distance := DistanceMetres(-1.27, 116.8, lat, lon); math.Abs(distance-5000) > 1 {
+		t.Errorf("distance to the destination is %.1f m, want 5000 m", distance)
+	}
+}
+
+func TestLandMaskContains(t *testing.T) {
+	mask, err := ParseLandMask([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [
+				[[116.0, -2.0], [117.0, -2.0], [117.0, -1.0], [116.0, -1.0], [116.0, -2.0]],
+				[[116.4, -1.6], [116.6, -1.6], [116.6, -1.4], [116.4, -1.4], [116.4, -1.6]]
+			]}},
+			{"type": "Feature", "properties": {}, "geometry": {"type": "MultiPolygon", "coordinates": [
+				[[[120.0, 0.0], [121.0, 0.0], [120.5, 1.0], [120.0, 0.0]]]
+			]}},
+			{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [0, 0]}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mask.Polygons) != 2 {
+		t.Fatalf("got %d polygons, want 2", len(mask.Polygons))
+	}
+
+	cases := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{"inside", -1.2, 116.2, true},
+		{"in the hole", -1.5, 116.5, false},
+		{"outside", -3.0, 116.5, false},
+		{"triangle", 0.3, 120.5, true},
+		{"beside the triangle", 0.9, 120.1, false},
+	}
+	for _, c := range cases {
+		if got := mask.Contains(c.lat, c.lon); got != c.want {
+			t.Errorf("%s: Contains(%v, %v) = %v, want %v", c.name, c.lat, c.lon, got, c.want)
+		}
+	}
+}
+
+func TestParseLandMaskWithoutPolygons(t *testing.T) {
+	if _, err := ParseLandMask([]byte(`{"type": "Point", "coordinates": [0, 0]}`)); err == nil {
+		t.Fatal("expected an error for a document without polygons")
+	}
+}
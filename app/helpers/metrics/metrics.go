@@ -0,0 +1,234 @@
+// Package metrics keeps counters and gauges and writes them in the
+// Prometheus text exposition format, without pulling in the Prometheus client.
+// Counters are updated as events happen; gauges are read from their owner
+// when the registry is written.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the exposition format written by WriteTo
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Default is the registry the ingest pipeline reports to
+var Default = NewRegistry()
+
+// Registry holds metric families by name
+type Registry struct {
+	mutex    sync.Mutex
+	families map[string]family
+}
+
+// family is a named metric with its help text and label names
+type family interface {
+	header() (name, help, kind string)
+	labelNames() []string
+	samples() []sample
+}
+
+type sample struct {
+	labels []string // Values, in the order of the family's label names
+	value  float64
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register adds a family, panicking on a duplicate name as that is a
+// programming error
+func (r *Registry) register(name string, f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, exists := r.families[name]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.families[name] = f
+}
+
+// CounterVec is a counter with one series per combination of label values
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	series map[string]*sample // key is the joined label values
+}
+
+// NewCounterVec registers a counter. Names should end in _total.
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, series: make(map[string]*sample)}
+	r.register(name, c)
+	return c
+}
+
+// Inc adds one to the series of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the series of the label values
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+	if value < 0 {
+		return
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s, exists := c.series[key]
+	if !exists {
+		s = &sample{labels: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += value
+}
+
+// Value returns the current value of the series of the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if s, exists := c.series[strings.Join(labelValues, "\xff")]; exists {
+		return s.value
+	}
+	return 0
+}
+
+func (c *CounterVec) header() (string, string, string) {
+	return c.name, c.help, "counter"
+}
+
+func (c *CounterVec) labelNames() []string {
+	return c.labels
+}
+
+func (c *CounterVec) samples() []sample {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	samples := make([]sample, 0, len(c.series))
+	for _, s := range c.series {
+		samples = append(samples, *s)
+	}
+	return samples
+}
+
+// GaugeFunc reports the current values of a gauge through emit, once per
+// series
+type GaugeFunc func(emit func(value float64, labelValues ...string))
+
+type gaugeFamily struct {
+	name    string
+	help    string
+	labels  []string
+	collect GaugeFunc
+}
+
+// NewGaugeFunc registers a gauge whose series are collected when the
+// registry is written
+func (r *Registry) NewGaugeFunc(name string, help string, labels []string, collect GaugeFunc) {
+	r.register(name, &gaugeFamily{name: name, help: help, labels: labels, collect: collect})
+}
+
+func (g *gaugeFamily) header() (string, string, string) {
+	return g.name, g.help, "gauge"
+}
+
+func (g *gaugeFamily) labelNames() []string {
+	return g.labels
+}
+
+func (g *gaugeFamily) samples() []sample {
+	var samples []sample
+	g.collect(func(value float64, labelValues ...string) {
+		if len(labelValues) != len(g.labels) {
+			panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", g.name, len(g.labels), len(labelValues)))
+		}
+		samples = append(samples, sample{labels: append([]string(nil), labelValues...), value: value})
+	})
+	return samples
+}
+
+// WriteTo writes every family in the text exposition format, families by
+// name and series by label values
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		families = append(families, r.families[name])
+	}
+	r.mutex.Unlock()
+
+	var b strings.Builder
+	for _, f := range families {
+		name, help, kind := f.header()
+		labels := f.labelNames()
+		samples := f.samples()
+		sort.Slice(samples, func(i, j int) bool {
+			return strings.Join(samples[i].labels, "\xff") < strings.Join(samples[j].labels, "\xff")
+		})
+
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, escapeHelp(help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, kind)
+		for _, s := range samples {
+			b.WriteString(name)
+			if len(labels) > 0 {
+				b.WriteByte('{')
+				for i, label := range labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(&b, "%s=\"%s\"", label, escapeLabel(s.labels[i]))
+				}
+				b.WriteByte('}')
+			}
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.value))
+			b.WriteByte('\n')
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	sentences := r.NewCounterVec("sentences_total", "Sentences received", "call_sign", "type")
+	sentences.Inc("YDAA", "GGA")
+	sentences.Inc("YDAA", "GGA")
+	sentences.Add(0.5, "PKXX", "HDT")
+	sentences.Add(-1, "PKXX", "HDT") // Counters never go down
+	if got := sentences.Value("YDAA", "GGA"); got != 2 {
+		t.Errorf("YDAA GGA is %v, want 2", got)
+	}
+	if got := sentences.Value("YDAA", "VTG"); got != 0 {
+		t.Errorf("unseen series is %v, want 0", got)
+	}
+
+	r.NewGaugeFunc("buffers", "Live buffers", nil, func(emit func(float64, ...string)) {
+		emit(3)
+	})
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP buffers Live buffers
+# TYPE buffers gauge
+buffers 3
+# HELP sentences_total Sentences received
+# TYPE sentences_total counter
+sentences_total{call_sign="PKXX",type="HDT"} 0.5
+sentences_total{call_sign="YDAA",type="GGA"} 2
+`
+	if b.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("session_state", "State of a session\\with \"quotes\"\nand lines", []string{"name"}, func(emit func(float64, ...string)) {
+		emit(1, "Sounder \"A\"\\1\n")
+		emit(math.Inf(1), "inf")
+	})
+
+	var b strings.Builder
+	r.WriteTo(&b)
+
+	for _, line := range []string{
+		`# HELP session_state State of a session\\with "quotes"\nand lines`,
+		`session_state{name="Sounder \"A\"\\1\n"} 1`,
+		`session_state{name="inf"} +Inf`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %q in\n%s", line, b.String())
+		}
+	}
+}
+
+func TestRegistrationMistakesPanic(t *testing.T) {
+	cases := map[string]func(r *Registry){
+		"duplicate name": func(r *Registry) {
+			r.NewCounterVec("lines_total", "")
+			r.NewCounterVec("lines_total", "")
+		},
+		"wrong label count": func(r *Registry) {
+			r.NewCounterVec("lines_total", "", "call_sign").Inc()
+		},
+	}
+	for name, register := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			register(NewRegistry())
+		}()
+	}
+}
@@ -0,0 +1,90 @@
+package nmea
+
+const (
+	TypeDBT = "DBT"
+	TypeDBS = "DBS"
+	TypeDPT = "DPT"
+
+	feetToMeters    = 0.3048
+	fathomsToMeters = 1.8288
+)
+
+// DBT is the depth below transducer in feet, metres and fathoms
+type DBT struct {
+	BaseSentence
+	DepthFeet    float64
+	DepthMeters  float64
+	DepthFathoms float64
+	HasDepth     bool
+}
+
+// Meters returns the depth in metres, converting from whichever unit was sent
+func (d DBT) Meters() float64 {
+	switch {
+	case d.DepthMeters > 0:
+		return d.DepthMeters
+	case d.DepthFeet > 0:
+		return d.DepthFeet * feetToMeters
+	case d.DepthFathoms > 0:
+		return d.DepthFathoms * fathomsToMeters
+	}
+	return 0
+}
+
+func decodeDBT(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	dbt := DBT{BaseSentence: s}
+	feet, hasFeet := r.Float(0, "depth feet")
+	meters, hasMeters := r.Float(2, "depth meters")
+	fathoms, hasFathoms := r.Float(4, "depth fathoms")
+	dbt.DepthFeet, dbt.DepthMeters, dbt.DepthFathoms = feet, meters, fathoms
+	dbt.HasDepth = hasFeet || hasMeters || hasFathoms
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return dbt, nil
+}
+
+// DBS is the depth below the water surface, in the same layout as DBT
+type DBS struct {
+	DBT
+}
+
+func decodeDBS(s BaseSentence) (Sentence, error) {
+	dbt, err := decodeDBT(s)
+	if err != nil {
+		return nil, err
+	}
+	return DBS{DBT: dbt.(DBT)}, nil
+}
+
+// DPT is the depth relative to the transducer plus the transducer offset
+type DPT struct {
+	BaseSentence
+	Depth    float64 // Metres below transducer
+	HasDepth bool
+	Offset   float64 // Metres; positive to waterline, negative to keel
+	MaxRange float64
+}
+
+func decodeDPT(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	dpt := DPT{BaseSentence: s}
+	dpt.Depth, dpt.HasDepth = r.Float(0, "depth")
+	dpt.Offset, _ = r.Float(1, "offset")
+	dpt.MaxRange, _ = r.Float(2, "max range")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return dpt, nil
+}
@@ -0,0 +1,57 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Encode builds a sentence with its checksum, e.g.
+// Encode("GP", "HDT", "274.07", "T") returns "$GPHDT,274.07,T*03"
+func Encode(talker string, sentenceType string, fields ...string) string {
+	body := talker + sentenceType
+	if len(fields) > 0 {
+		body += "," + strings.Join(fields, ",")
+	}
+	return fmt.Sprintf("$%s*%02X", body, Checksum(body))
+}
+
+// FormatLatitude returns the ddmm.mmmm field and N/S hemisphere of a latitude
+func FormatLatitude(degrees float64) (string, string) {
+	hemisphere := "N"
+	if degrees < 0 {
+		hemisphere = "S"
+	}
+	return formatAngle(math.Abs(degrees), 2), hemisphere
+}
+
+// FormatLongitude returns the dddmm.mmmm field and E/W hemisphere of a longitude
+func FormatLongitude(degrees float64) (string, string) {
+	hemisphere := "E"
+	if degrees < 0 {
+		hemisphere = "W"
+	}
+	return formatAngle(math.Abs(degrees), 3), hemisphere
+}
+
+func formatAngle(degrees float64, width int) string {
+	whole := math.Floor(degrees)
+	minutes := (degrees - whole) * 60
+	// Rounding can carry the minutes up to 60
+	if minutes >= 59.99995 {
+		whole, minutes = whole+1, 0
+	}
+	return fmt.Sprintf("%0*d%07.4f", width, int(whole), minutes)
+}
+
+// FormatTime returns the hhmmss.ss time field of a UTC time
+func FormatTime(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%02d%02d%05.2f", t.Hour(), t.Minute(), float64(t.Second())+float64(t.Nanosecond())/1e9)
+}
+
+// FormatDate returns the ddmmyy date field of a UTC time
+func FormatDate(t time.Time) string {
+	return t.UTC().Format("020106")
+}
@@ -0,0 +1,172 @@
+package nmea
+
+const (
+	TypeMTW = "MTW"
+	TypeMWV = "MWV"
+	TypeMDA = "MDA"
+	TypeXDR = "XDR"
+
+	kmhToKnots = 1 / 1.852
+	msToKnots  = 3600 / 1852.0
+	mphToKnots = 1609.344 / 1852
+)
+
+// MTW is the water temperature
+type MTW struct {
+	BaseSentence
+	Temperature    float64 // Degrees Celsius
+	HasTemperature bool
+}
+
+func decodeMTW(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	mtw := MTW{BaseSentence: s}
+	mtw.Temperature, mtw.HasTemperature = r.Float(0, "temperature")
+	if unit := r.String(1); unit != "" && unit != "C" {
+		r.fail("temperature unit", unit)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return mtw, nil
+}
+
+// MWV is the wind speed and angle, relative to the bow or true
+type MWV struct {
+	BaseSentence
+	Angle      float64 // Degrees from the bow
+	HasAngle   bool
+	Reference  string  // R relative (apparent), T theoretical (true)
+	SpeedKnots float64 // Converted from the sentence's speed unit
+	HasSpeed   bool
+	Valid      bool
+}
+
+func decodeMWV(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	mwv := MWV{BaseSentence: s}
+	mwv.Angle, mwv.HasAngle = r.Float(0, "wind angle")
+	mwv.Reference = r.String(1)
+	mwv.Valid = r.String(4) == "A"
+
+	if speed, ok := r.Float(2, "wind speed"); ok {
+		switch unit := r.String(3); unit {
+		case "N":
+			mwv.SpeedKnots = speed
+		case "K":
+			mwv.SpeedKnots = speed * kmhToKnots
+		case "M":
+			mwv.SpeedKnots = speed * msToKnots
+		case "S":
+			mwv.SpeedKnots = speed * mphToKnots
+		default:
+			r.fail("wind speed unit", unit)
+		}
+		mwv.HasSpeed = r.err == nil
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return mwv, nil
+}
+
+// MDA is the meteorological composite. Each value has its own Has flag as
+// instruments usually only fill in the fields they measure.
+type MDA struct {
+	BaseSentence
+	PressureBar         float64
+	HasPressure         bool
+	AirTemperature      float64 // Degrees Celsius
+	HasAirTemperature   bool
+	WaterTemperature    float64 // Degrees Celsius
+	HasWaterTemperature bool
+	RelativeHumidity    float64 // Percent
+	HasRelativeHumidity bool
+	DewPoint            float64 // Degrees Celsius
+	HasDewPoint         bool
+	WindDirection       float64 // Degrees true the wind blows from
+	HasWindDirection    bool
+	WindSpeedKnots      float64
+	HasWindSpeed        bool
+}
+
+func decodeMDA(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	mda := MDA{BaseSentence: s}
+	mda.PressureBar, mda.HasPressure = r.Float(2, "pressure bars")
+	if !mda.HasPressure {
+		if inches, ok := r.Float(0, "pressure inches"); ok {
+			mda.PressureBar, mda.HasPressure = inches*0.0338639, true
+		}
+	}
+	mda.AirTemperature, mda.HasAirTemperature = r.Float(4, "air temperature")
+	mda.WaterTemperature, mda.HasWaterTemperature = r.Float(6, "water temperature")
+	mda.RelativeHumidity, mda.HasRelativeHumidity = r.Float(8, "relative humidity")
+	mda.DewPoint, mda.HasDewPoint = r.Float(10, "dew point")
+	mda.WindDirection, mda.HasWindDirection = r.Float(12, "wind direction")
+	mda.WindSpeedKnots, mda.HasWindSpeed = r.Float(16, "wind speed knots")
+	if !mda.HasWindSpeed {
+		if ms, ok := r.Float(18, "wind speed m/s"); ok {
+			mda.WindSpeedKnots, mda.HasWindSpeed = ms*msToKnots, true
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return mda, nil
+}
+
+// Transducer is one measurement of an XDR sentence
+type Transducer struct {
+	Type  string // e.g. C temperature, P pressure, H humidity, A angle
+	Value float64
+	Unit  string
+	Name  string
+}
+
+// XDR carries any number of generic transducer measurements
+type XDR struct {
+	BaseSentence
+	Measurements []Transducer
+}
+
+func decodeXDR(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	xdr := XDR{BaseSentence: s}
+	for i := 0; i+3 < len(s.Fields); i += 4 {
+		value, ok := r.Float(i+1, "transducer value")
+		if !ok {
+			continue
+		}
+		xdr.Measurements = append(xdr.Measurements, Transducer{
+			Type:  r.String(i),
+			Value: value,
+			Unit:  r.String(i + 2),
+			Name:  r.String(i + 3),
+		})
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return xdr, nil
+}
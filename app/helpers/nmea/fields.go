@@ -0,0 +1,136 @@
+package nmea
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldReader reads typed values out of a sentence's fields and keeps the
+// first error it runs into, so decoders can read every field and check once
+type fieldReader struct {
+	fields []string
+	err    error
+}
+
+func newFieldReader(s BaseSentence, minFields int) (*fieldReader, error) {
+	if len(s.Fields) < minFields {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrTooFewFields, len(s.Fields), minFields)
+	}
+	return &fieldReader{fields: s.Fields}, nil
+}
+
+// String returns the trimmed field or an empty string if it is missing
+func (r *fieldReader) String(i int) string {
+	if i >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+// Float returns the field as a float; empty fields are reported as not set
+func (r *fieldReader) Float(i int, name string) (float64, bool) {
+	value := r.String(i)
+	if value == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.fail(name, value)
+		return 0, false
+	}
+	return f, true
+}
+
+// Int returns the field as an int; empty fields are reported as not set
+func (r *fieldReader) Int(i int, name string) (int, bool) {
+	value := r.String(i)
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail(name, value)
+		return 0, false
+	}
+	return n, true
+}
+
+// LatLon reads a ddmm.mmmm / dddmm.mmmm value followed by its hemisphere
+// field and returns signed decimal degrees
+func (r *fieldReader) LatLon(i int, name string, positive, negative string) (float64, bool) {
+	value, hemisphere := r.String(i), strings.ToUpper(r.String(i+1))
+	if value == "" && hemisphere == "" {
+		return 0, false
+	}
+
+	raw, err := strconv.ParseFloat(value, 64)
+	if err != nil || raw < 0 {
+		r.fail(name, value)
+		return 0, false
+	}
+
+	degrees := float64(int(raw / 100))
+	minutes := raw - degrees*100
+	if minutes >= 60 {
+		r.fail(name, value)
+		return 0, false
+	}
+
+	decimal := degrees + minutes/60
+	switch hemisphere {
+	case positive:
+	case negative:
+		decimal = -decimal
+	default:
+		r.fail(name+" hemisphere", hemisphere)
+		return 0, false
+	}
+	return decimal, true
+}
+
+// Time reads an hhmmss(.ss) UTC time of day
+func (r *fieldReader) Time(i int, name string) (time.Duration, bool) {
+	value := r.String(i)
+	if value == "" {
+		return 0, false
+	}
+	if len(value) < 6 {
+		r.fail(name, value)
+		return 0, false
+	}
+
+	hours, errH := strconv.Atoi(value[0:2])
+	minutes, errM := strconv.Atoi(value[2:4])
+	seconds, errS := strconv.ParseFloat(value[4:], 64)
+	if errH != nil || errM != nil || errS != nil || hours > 23 || minutes > 59 || seconds >= 61 {
+		r.fail(name, value)
+		return 0, false
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), true
+}
+
+// Date reads a ddmmyy UTC date
+func (r *fieldReader) Date(i int, name string) (time.Time, bool) {
+	value := r.String(i)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("020106", value)
+	if err != nil {
+		r.fail(name, value)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (r *fieldReader) fail(name, value string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s %q", ErrInvalidField, name, value)
+	}
+}
@@ -0,0 +1,45 @@
+package nmea
+
+import "time"
+
+const TypeGGA = "GGA"
+
+// GGA is a GNSS fix: time, position and fix related data
+type GGA struct {
+	BaseSentence
+	Time          time.Duration // UTC time of day
+	HasTime       bool
+	Latitude      float64 // Signed decimal degrees, north positive
+	Longitude     float64 // Signed decimal degrees, east positive
+	HasPosition   bool
+	FixQuality    int // 0 invalid, 1 GPS, 2 DGPS, 4 RTK fixed, 5 RTK float, 6 dead reckoning...
+	NumSatellites int
+	HDOP          float64
+	Altitude      float64 // Metres above mean sea level
+}
+
+func decodeGGA(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	gga := GGA{BaseSentence: s}
+	gga.Time, gga.HasTime = r.Time(0, "time")
+
+	lat, hasLat := r.LatLon(1, "latitude", "N", "S")
+	lon, hasLon := r.LatLon(3, "longitude", "E", "W")
+	if hasLat && hasLon {
+		gga.Latitude, gga.Longitude, gga.HasPosition = lat, lon, true
+	}
+
+	gga.FixQuality, _ = r.Int(5, "fix quality")
+	gga.NumSatellites, _ = r.Int(6, "satellites")
+	gga.HDOP, _ = r.Float(7, "hdop")
+	gga.Altitude, _ = r.Float(8, "altitude")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return gga, nil
+}
@@ -0,0 +1,78 @@
+package nmea
+
+const (
+	TypeHDG = "HDG"
+	TypeHDM = "HDM"
+)
+
+// HDG is the magnetic sensor heading with deviation and variation
+type HDG struct {
+	BaseSentence
+	Heading      float64 // Magnetic sensor heading, degrees
+	HasHeading   bool
+	Deviation    float64 // Degrees, east positive
+	HasDeviation bool
+	Variation    float64 // Degrees, east positive
+	HasVariation bool
+}
+
+func decodeHDG(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	hdg := HDG{BaseSentence: s}
+	hdg.Heading, hdg.HasHeading = r.Float(0, "heading")
+	hdg.Deviation, hdg.HasDeviation = r.Float(1, "deviation")
+	if r.String(2) == "W" {
+		hdg.Deviation = -hdg.Deviation
+	}
+	hdg.Variation, hdg.HasVariation = r.Float(3, "variation")
+	if r.String(4) == "W" {
+		hdg.Variation = -hdg.Variation
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return hdg, nil
+}
+
+// Magnetic returns the heading corrected for deviation, in degrees magnetic
+func (h HDG) Magnetic() float64 {
+	return NormalizeDegrees(h.Heading + h.Deviation)
+}
+
+// HDM is the heading in degrees magnetic
+type HDM struct {
+	BaseSentence
+	Heading    float64
+	HasHeading bool
+}
+
+func decodeHDM(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	hdm := HDM{BaseSentence: s}
+	hdm.Heading, hdm.HasHeading = r.Float(0, "heading")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return hdm, nil
+}
+
+// NormalizeDegrees wraps an angle into the range [0, 360)
+func NormalizeDegrees(degrees float64) float64 {
+	for degrees < 0 {
+		degrees += 360
+	}
+	for degrees >= 360 {
+		degrees -= 360
+	}
+	return degrees
+}
@@ -0,0 +1,25 @@
+package nmea
+
+const TypeHDT = "HDT"
+
+// HDT is the true heading from a gyro or GNSS compass
+type HDT struct {
+	BaseSentence
+	Heading    float64 // Degrees true
+	HasHeading bool
+}
+
+func decodeHDT(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	hdt := HDT{BaseSentence: s}
+	hdt.Heading, hdt.HasHeading = r.Float(0, "heading")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return hdt, nil
+}
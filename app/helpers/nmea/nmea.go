@@ -0,0 +1,213 @@
+// Package nmea parses NMEA 0183 sentences into typed structs.
+//
+// Every sentence is checked for framing, checksum and talker ID before the
+// type specific decoder runs. When a sentence is rejected the returned error
+// wraps one of the Err* values below so callers can tell why.
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmpty            = errors.New("empty sentence")
+	ErrInvalidStart     = errors.New("sentence must start with '$' or '!'")
+	ErrMissingChecksum  = errors.New("missing checksum")
+	ErrInvalidChecksum  = errors.New("malformed checksum")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrInvalidTalker    = errors.New("invalid talker id")
+	ErrUnsupportedType  = errors.New("unsupported sentence type")
+	ErrInvalidField     = errors.New("invalid field")
+	ErrTooFewFields     = errors.New("too few fields")
+)
+
+// KnownTalkers lists the talker IDs accepted by the parser
+var KnownTalkers = map[string]bool{
+	"GP": true, // GPS
+	"GL": true, // GLONASS
+	"GA": true, // Galileo
+	"GB": true, // BeiDou
+	"BD": true, // BeiDou (legacy)
+	"GQ": true, // QZSS
+	"GI": true, // NavIC
+	"GN": true, // Combined GNSS
+	"HC": true, // Magnetic compass
+	"HE": true, // North seeking gyro
+	"HN": true, // Non north seeking gyro
+	"II": true, // Integrated instrumentation
+	"IN": true, // Integrated navigation
+	"SD": true, // Depth sounder
+	"VW": true, // Mechanical speed log
+	"VD": true, // Doppler speed log
+	"WI": true, // Weather instruments
+	"YX": true, // Transducer
+	"AI": true, // AIS mobile station
+	"AB": true, // AIS base station
+	"AD": true, // AIS dependent base station
+	"AN": true, // AIS aid to navigation
+	"AR": true, // AIS receiving station
+	"AS": true, // AIS limited base station
+	"AT": true, // AIS transmitting station
+	"AX": true, // AIS simplex repeater
+	"BS": true, // AIS base station (legacy)
+	"SA": true, // AIS physical shore station
+	"EC": true, // ECDIS
+}
+
+// Sentence is implemented by every decoded sentence type
+type Sentence interface {
+	Prefix() string
+}
+
+// BaseSentence holds the parts common to every sentence
+type BaseSentence struct {
+	Talker   string   // Talker ID, e.g. "GP"
+	Type     string   // Sentence type, e.g. "GGA"
+	Fields   []string // Data fields after the address field
+	Checksum string   // Checksum as received, empty if absent
+	Raw      string   // Original sentence
+}
+
+// Prefix returns the talker and type, e.g. "GPGGA"
+func (s BaseSentence) Prefix() string {
+	return s.Talker + s.Type
+}
+
+// Parser validates and decodes NMEA sentences
+type Parser struct {
+	// RequireChecksum rejects sentences that carry no *hh suffix
+	RequireChecksum bool
+	// AllowUnknownTalkers accepts well-formed talker IDs that are not in KnownTalkers
+	AllowUnknownTalkers bool
+}
+
+var defaultParser = &Parser{}
+
+// Parse decodes a sentence with the default parser
+func Parse(raw string) (Sentence, error) {
+	return defaultParser.Parse(raw)
+}
+
+// Parse validates the sentence and decodes it into its typed struct
+func (p *Parser) Parse(raw string) (Sentence, error) {
+	base, err := p.ParseBase(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, ok := decoders[base.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, base.Prefix())
+	}
+
+	sentence, err := decoder(base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", base.Prefix(), err)
+	}
+	return sentence, nil
+}
+
+// ParseBase checks framing, checksum and talker ID without decoding the fields
+func (p *Parser) ParseBase(raw string) (BaseSentence, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return BaseSentence{}, ErrEmpty
+	}
+	if raw[0] != '$' && raw[0] != '!' {
+		return BaseSentence{}, ErrInvalidStart
+	}
+
+	body := raw[1:]
+	checksum := ""
+	if idx := strings.IndexByte(body, '*'); idx >= 0 {
+		checksum = body[idx+1:]
+		body = body[:idx]
+
+		if len(checksum) != 2 {
+			return BaseSentence{}, fmt.Errorf("%w: %q", ErrInvalidChecksum, checksum)
+		}
+		expected, err := strconv.ParseUint(checksum, 16, 8)
+		if err != nil {
+			return BaseSentence{}, fmt.Errorf("%w: %q", ErrInvalidChecksum, checksum)
+		}
+		if actual := Checksum(body); byte(expected) != actual {
+			return BaseSentence{}, fmt.Errorf("%w: got %02X, want %02X", ErrChecksumMismatch, byte(expected), actual)
+		}
+	} else if p.RequireChecksum {
+		return BaseSentence{}, ErrMissingChecksum
+	}
+
+	parts := strings.Split(body, ",")
+	address := parts[0]
+	if len(address) < 5 {
+		return BaseSentence{}, fmt.Errorf("%w: %q", ErrInvalidTalker, address)
+	}
+
+	talker, sentenceType := address[:2], address[2:]
+	if address[0] == 'P' {
+		// Proprietary sentences use 'P' followed by a manufacturer code
+		talker, sentenceType = "P", address[1:]
+	} else if !p.validTalker(talker) {
+		return BaseSentence{}, fmt.Errorf("%w: %q", ErrInvalidTalker, talker)
+	}
+
+	return BaseSentence{
+		Talker:   talker,
+		Type:     sentenceType,
+		Fields:   parts[1:],
+		Checksum: strings.ToUpper(checksum),
+		Raw:      raw,
+	}, nil
+}
+
+// validTalker reports whether the talker ID is acceptable
+func (p *Parser) validTalker(talker string) bool {
+	for i := 0; i < len(talker); i++ {
+		if talker[i] < 'A' || talker[i] > 'Z' {
+			return false
+		}
+	}
+	return p.AllowUnknownTalkers || KnownTalkers[talker]
+}
+
+// Checksum computes the XOR checksum of the characters between '$' and '*'
+func Checksum(body string) byte {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return sum
+}
+
+// SentenceType returns the three letter type of a raw sentence, e.g. "GGA"
+func SentenceType(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 6 || (raw[0] != '$' && raw[0] != '!') {
+		return ""
+	}
+	return raw[3:6]
+}
+
+type decoder func(BaseSentence) (Sentence, error)
+
+var decoders = map[string]decoder{
+	TypeGGA: decodeGGA,
+	TypeRMC: decodeRMC,
+	TypeHDT: decodeHDT,
+	TypeHDG: decodeHDG,
+	TypeHDM: decodeHDM,
+	TypeZDA: decodeZDA,
+	TypeVTG: decodeVTG,
+	TypeDBT: decodeDBT,
+	TypeDBS: decodeDBS,
+	TypeDPT: decodeDPT,
+	TypeMTW: decodeMTW,
+	TypeMWV: decodeMWV,
+	TypeMDA: decodeMDA,
+	TypeXDR: decodeXDR,
+	TypeVDM: decodeVDM,
+	TypeVDO: decodeVDM,
+}
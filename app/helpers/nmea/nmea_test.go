@@ -0,0 +1,189 @@
+package nmea
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseRejectsBadSentences(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser *Parser
+		raw    string
+		want   error
+	}{
+		{"empty", defaultParser, "  ", ErrEmpty},
+		{"no start", defaultParser, "GPHDT,274.07,T*03", ErrInvalidStart},
+		{"checksum mismatch", defaultParser, "$GPHDT,274.07,T*04", ErrChecksumMismatch},
+		{"malformed checksum", defaultParser, "$GPHDT,274.07,T*0", ErrInvalidChecksum},
+		{"missing checksum", &Parser{RequireChecksum: true}, "$GPHDT,274.07,T", ErrMissingChecksum},
+		{"unknown talker", defaultParser, "$ZZHDT,274.07,T", ErrInvalidTalker},
+		{"lowercase talker", &Parser{AllowUnknownTalkers: true}, "$gpHDT,274.07,T", ErrInvalidTalker},
+		{"unsupported type", defaultParser, "$GPXXX,1,2,3", ErrUnsupportedType},
+		{"bad field", defaultParser, "$GPHDT,27x.07,T", ErrInvalidField},
+		{"too few fields", defaultParser, "$GPGGA,123519,4807.038,N", ErrTooFewFields},
+		{"too many fragments", defaultParser, "!AIVDM,999999999,1,,A,177KQJ5000G?tO`K>RA1wUbN0TKH,0", ErrInvalidField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parser.Parse(tt.raw)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.raw, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGGA(t *testing.T) {
+	sentence, err := Parse("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gga, ok := sentence.(GGA)
+	if !ok {
+		t.Fatalf("got %T, want GGA", sentence)
+	}
+	if !gga.HasPosition || math.Abs(gga.Latitude-48.1173) > 1e-4 || math.Abs(gga.Longitude-11.516667) > 1e-4 {
+		t.Errorf("position = %v,%v", gga.Latitude, gga.Longitude)
+	}
+	if gga.FixQuality != 1 || gga.NumSatellites != 8 {
+		t.Errorf("fix quality = %d, satellites = %d", gga.FixQuality, gga.NumSatellites)
+	}
+}
+
+func TestParseVTGAndDepth(t *testing.T) {
+	sentence, err := Parse("$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vtg := sentence.(VTG); !vtg.HasSpeed || vtg.SpeedKnots != 5.5 || vtg.TrueTrack != 54.7 {
+		t.Errorf("vtg = %+v", vtg)
+	}
+
+	sentence, err = Parse("$SDDBT,32.8,f,10.0,M,5.5,F")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dbt := sentence.(DBT); dbt.Meters() != 10.0 {
+		t.Errorf("dbt meters = %v", dbt.Meters())
+	}
+}
+
+func TestParseRMC(t *testing.T) {
+	sentence, err := Parse("$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rmc := sentence.(RMC)
+	if !rmc.Valid || !rmc.HasPosition || rmc.SpeedKnots != 22.4 || rmc.Course != 84.4 {
+		t.Errorf("rmc = %+v", rmc)
+	}
+	if want := "1994-03-23T12:35:19Z"; rmc.DateTime.Format("2006-01-02T15:04:05Z07:00") != want {
+		t.Errorf("date time = %v, want %s", rmc.DateTime, want)
+	}
+	if rmc.MagneticVariation != -3.1 {
+		t.Errorf("magnetic variation = %v", rmc.MagneticVariation)
+	}
+}
+
+func TestParseHDG(t *testing.T) {
+	sentence, err := Parse("$HCHDG,98.3,0.5,E,3.1,W")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hdg := sentence.(HDG)
+	if !hdg.HasHeading || hdg.Deviation != 0.5 || hdg.Variation != -3.1 || !hdg.HasVariation {
+		t.Errorf("hdg = %+v", hdg)
+	}
+	if math.Abs(hdg.Magnetic()-98.8) > 1e-9 {
+		t.Errorf("magnetic = %v", hdg.Magnetic())
+	}
+	if NormalizeDegrees(-5) != 355 || NormalizeDegrees(725) != 5 {
+		t.Error("NormalizeDegrees did not wrap")
+	}
+}
+
+func TestParseZDAAndResolveTimeOfDay(t *testing.T) {
+	sentence, err := Parse("$GPZDA,201530.00,04,07,2002,00,00*60")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zda := sentence.(ZDA)
+	want := time.Date(2002, 7, 4, 20, 15, 30, 0, time.UTC)
+	if !zda.HasDateTime || !zda.DateTime.Equal(want) {
+		t.Errorf("date time = %v, want %v", zda.DateTime, want)
+	}
+
+	// A fix from just before midnight that arrives just after it belongs to the previous day
+	reference := time.Date(2024, 3, 2, 0, 0, 5, 0, time.UTC)
+	resolved := ResolveTimeOfDay(23*time.Hour+59*time.Minute+58*time.Second, reference)
+	if want := time.Date(2024, 3, 1, 23, 59, 58, 0, time.UTC); !resolved.Equal(want) {
+		t.Errorf("resolved = %v, want %v", resolved, want)
+	}
+}
+
+func TestParseEnvironment(t *testing.T) {
+	sentence, err := Parse("$IIMTW,24.5,C*10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mtw := sentence.(MTW); !mtw.HasTemperature || mtw.Temperature != 24.5 {
+		t.Errorf("mtw = %+v", mtw)
+	}
+
+	sentence, err = Parse("$WIMWV,045.0,R,10.0,M,A*10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mwv := sentence.(MWV)
+	if !mwv.Valid || mwv.Reference != "R" || mwv.Angle != 45 || math.Abs(mwv.SpeedKnots-19.438) > 1e-3 {
+		t.Errorf("mwv = %+v", mwv)
+	}
+
+	sentence, err = Parse("$WIMDA,29.9350,I,1.0137,B,26.3,C,,C,71.0,,20.6,C,210.0,T,209.6,M,6.8,N,3.5,M*29")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mda := sentence.(MDA)
+	if mda.PressureBar != 1.0137 || mda.AirTemperature != 26.3 || mda.HasWaterTemperature || mda.WindSpeedKnots != 6.8 {
+		t.Errorf("mda = %+v", mda)
+	}
+
+	sentence, err = Parse("$YXXDR,C,19.5,C,AIRTEMP,P,1.0135,B,BARO*1E")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	xdr := sentence.(XDR)
+	if len(xdr.Measurements) != 2 || xdr.Measurements[1].Name != "BARO" || xdr.Measurements[1].Unit != "B" {
+		t.Errorf("xdr = %+v", xdr)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	if got := Encode("GP", "HDT", "274.07", "T"); got != "$GPHDT,274.07,T*03" {
+		t.Fatalf("Encode = %q", got)
+	}
+
+	lat, ns := FormatLatitude(-1.2654)
+	lon, ew := FormatLongitude(116.8312)
+	fixTime := time.Date(2025, 3, 1, 10, 4, 5, 500000000, time.UTC)
+	sentence, err := Parse(Encode("GP", "GGA", FormatTime(fixTime), lat, ns, lon, ew, "1", "10", "0.9", "0.0", "M", "0.0", "M", "", ""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gga := sentence.(GGA)
+	if math.Abs(gga.Latitude+1.2654) > 1e-6 || math.Abs(gga.Longitude-116.8312) > 1e-6 {
+		t.Errorf("position = %f, %f", gga.Latitude, gga.Longitude)
+	}
+	if want := 10*time.Hour + 4*time.Minute + 5500*time.Millisecond; gga.Time != want {
+		t.Errorf("time = %v, want %v", gga.Time, want)
+	}
+}
@@ -0,0 +1,61 @@
+package nmea
+
+import "time"
+
+const TypeRMC = "RMC"
+
+// RMC is the recommended minimum navigation data: position, speed, course and date
+type RMC struct {
+	BaseSentence
+	DateTime          time.Time // UTC date and time of the fix
+	HasDateTime       bool
+	Valid             bool    // Status field 'A'; 'V' is a navigation receiver warning
+	Latitude          float64 // Signed decimal degrees, north positive
+	Longitude         float64 // Signed decimal degrees, east positive
+	HasPosition       bool
+	SpeedKnots        float64
+	HasSpeed          bool
+	Course            float64 // Course over ground, degrees true
+	HasCourse         bool
+	MagneticVariation float64 // Degrees, east positive
+	HasVariation      bool
+	Mode              string // FAA mode indicator (A, D, E, F, R, N...), NMEA 2.3 and later
+}
+
+func decodeRMC(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 9)
+	if err != nil {
+		return nil, err
+	}
+
+	rmc := RMC{BaseSentence: s}
+	timeOfDay, hasTime := r.Time(0, "time")
+	rmc.Valid = r.String(1) == "A"
+
+	lat, hasLat := r.LatLon(2, "latitude", "N", "S")
+	lon, hasLon := r.LatLon(4, "longitude", "E", "W")
+	if hasLat && hasLon {
+		rmc.Latitude, rmc.Longitude, rmc.HasPosition = lat, lon, true
+	}
+
+	rmc.SpeedKnots, rmc.HasSpeed = r.Float(6, "speed knots")
+	rmc.Course, rmc.HasCourse = r.Float(7, "course")
+
+	date, hasDate := r.Date(8, "date")
+	if hasDate && hasTime {
+		rmc.DateTime, rmc.HasDateTime = date.Add(timeOfDay), true
+	}
+
+	if variation, ok := r.Float(9, "magnetic variation"); ok {
+		if r.String(10) == "W" {
+			variation = -variation
+		}
+		rmc.MagneticVariation, rmc.HasVariation = variation, true
+	}
+	rmc.Mode = r.String(11)
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return rmc, nil
+}
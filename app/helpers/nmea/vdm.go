@@ -0,0 +1,50 @@
+package nmea
+
+import "fmt"
+
+const (
+	TypeVDM = "VDM"
+	TypeVDO = "VDO"
+)
+
+// maxVDMFragments bounds the fragment count, a single digit in the sentence
+const maxVDMFragments = 9
+
+// VDM is one fragment of an AIS message. VDO sentences carry the own ship's
+// reports and decode into the same struct with OwnShip set.
+type VDM struct {
+	BaseSentence
+	FragmentCount  int
+	FragmentNumber int
+	MessageID      string // Sequential message ID linking multi-fragment messages
+	Channel        string // Radio channel, A or B
+	Payload        string // Six-bit armoured payload
+	FillBits       int
+	OwnShip        bool
+}
+
+func decodeVDM(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	vdm := VDM{BaseSentence: s, OwnShip: s.Type == TypeVDO}
+	vdm.FragmentCount, _ = r.Int(0, "fragment count")
+	vdm.FragmentNumber, _ = r.Int(1, "fragment number")
+	vdm.MessageID = r.String(2)
+	vdm.Channel = r.String(3)
+	vdm.Payload = r.String(4)
+	vdm.FillBits, _ = r.Int(5, "fill bits")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if vdm.FragmentCount < 1 || vdm.FragmentCount > maxVDMFragments || vdm.FragmentNumber < 1 || vdm.FragmentNumber > vdm.FragmentCount {
+		return nil, fmt.Errorf("%w: fragment %d of %d", ErrInvalidField, vdm.FragmentNumber, vdm.FragmentCount)
+	}
+	if vdm.FillBits < 0 || vdm.FillBits > 5 {
+		return nil, fmt.Errorf("%w: fill bits %d", ErrInvalidField, vdm.FillBits)
+	}
+	return vdm, nil
+}
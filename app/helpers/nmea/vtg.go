@@ -0,0 +1,39 @@
+package nmea
+
+const TypeVTG = "VTG"
+
+// VTG is course and speed over ground
+type VTG struct {
+	BaseSentence
+	TrueTrack     float64 // Degrees true
+	HasTrueTrack  bool
+	MagneticTrack float64 // Degrees magnetic
+	SpeedKnots    float64
+	HasSpeed      bool
+	SpeedKmh      float64
+	Mode          string // FAA mode indicator (A, D, E, N...), NMEA 2.3 and later
+}
+
+func decodeVTG(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	vtg := VTG{BaseSentence: s}
+	vtg.TrueTrack, vtg.HasTrueTrack = r.Float(0, "true track")
+	vtg.MagneticTrack, _ = r.Float(2, "magnetic track")
+	vtg.SpeedKnots, vtg.HasSpeed = r.Float(4, "speed knots")
+	vtg.SpeedKmh, _ = r.Float(6, "speed kmh")
+	vtg.Mode = r.String(8)
+
+	// Some receivers only fill in the km/h field
+	if !vtg.HasSpeed && vtg.SpeedKmh > 0 {
+		vtg.SpeedKnots, vtg.HasSpeed = vtg.SpeedKmh/1.852, true
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return vtg, nil
+}
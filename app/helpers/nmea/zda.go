@@ -0,0 +1,59 @@
+package nmea
+
+import "time"
+
+const TypeZDA = "ZDA"
+
+// ZDA is the UTC date and time with the local zone offset
+type ZDA struct {
+	BaseSentence
+	DateTime    time.Time // UTC
+	HasDateTime bool
+	ZoneHours   int // Local zone offset from UTC
+	ZoneMinutes int
+}
+
+func decodeZDA(s BaseSentence) (Sentence, error) {
+	r, err := newFieldReader(s, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	zda := ZDA{BaseSentence: s}
+	timeOfDay, hasTime := r.Time(0, "time")
+	day, hasDay := r.Int(1, "day")
+	month, hasMonth := r.Int(2, "month")
+	year, hasYear := r.Int(3, "year")
+	zda.ZoneHours, _ = r.Int(4, "zone hours")
+	zda.ZoneMinutes, _ = r.Int(5, "zone minutes")
+
+	if hasTime && hasDay && hasMonth && hasYear {
+		if day < 1 || day > 31 || month < 1 || month > 12 {
+			r.fail("date", r.String(1)+"/"+r.String(2)+"/"+r.String(3))
+		} else {
+			zda.DateTime = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Add(timeOfDay)
+			zda.HasDateTime = true
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return zda, nil
+}
+
+// ResolveTimeOfDay attaches a date to a UTC time of day, such as the one in
+// GGA, picking the day that puts it closest to reference
+func ResolveTimeOfDay(timeOfDay time.Duration, reference time.Time) time.Time {
+	reference = reference.UTC()
+	midnight := time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, time.UTC)
+	resolved := midnight.Add(timeOfDay)
+
+	switch diff := resolved.Sub(reference); {
+	case diff > 12*time.Hour:
+		resolved = resolved.AddDate(0, 0, -1)
+	case diff < -12*time.Hour:
+		resolved = resolved.AddDate(0, 0, 1)
+	}
+	return resolved
+}
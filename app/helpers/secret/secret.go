@@ -0,0 +1,39 @@
+// Package secret generates and checks the device secrets vessels present
+// when they connect. Only the SHA-256 hash of a secret is stored.
+package secret
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// secretBytes is the amount of randomness in a generated secret
+const secretBytes = 24
+
+// Generate returns a new random secret and the hash to store for it
+func Generate() (secret string, hash string, err error) {
+	raw := make([]byte, secretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	secret = hex.EncodeToString(raw)
+	return secret, Hash(secret), nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of a secret
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether secret hashes to the stored hash. An empty stored
+// hash never matches.
+func Matches(hash, secret string) bool {
+	if hash == "" || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(Hash(secret))) == 1
+}
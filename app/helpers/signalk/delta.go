@@ -0,0 +1,189 @@
+// Package signalk reads and writes Signal K delta messages and translates
+// them into the NMEA sentence types the navigation pipeline already handles.
+package signalk
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"goravel/app/helpers/nmea"
+)
+
+// Signal K paths the pipeline understands
+const (
+	PathPosition         = "navigation.position"
+	PathHeadingTrue      = "navigation.headingTrue"
+	PathHeadingMagnetic  = "navigation.headingMagnetic"
+	PathSpeedOverGround  = "navigation.speedOverGround"
+	PathCourseOverGround = "navigation.courseOverGroundTrue"
+	PathDepthTransducer  = "environment.depth.belowTransducer"
+	PathWaterTemperature = "environment.water.temperature"
+	PathWindSpeed        = "environment.wind.speedApparent"
+	PathWindAngle        = "environment.wind.angleApparent"
+)
+
+const (
+	msToKnots    = 3600 / 1852.0
+	kelvinOffset = 273.15
+)
+
+// ErrNotDelta is returned for messages that are not deltas, such as the hello message
+var ErrNotDelta = errors.New("signalk: not a delta message")
+
+// Delta is a Signal K delta message
+type Delta struct {
+	Context string   `json:"context,omitempty"`
+	Updates []Update `json:"updates"`
+}
+
+// Update is one group of values sharing a source and timestamp
+type Update struct {
+	Source    *Source   `json:"source,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Values    []Value   `json:"values"`
+}
+
+// Source identifies the device an update came from
+type Source struct {
+	Label    string `json:"label"`
+	Type     string `json:"type,omitempty"`
+	Talker   string `json:"talker,omitempty"`
+	Sentence string `json:"sentence,omitempty"`
+}
+
+// Value is a single path and its value, in SI units
+type Value struct {
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Position is the value of navigation.position
+type Position struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Hello is the first message a Signal K server sends on a stream
+type Hello struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Self    string `json:"self"`
+}
+
+// Decode parses a stream message. Messages without updates, such as the
+// hello message, return ErrNotDelta.
+func Decode(data []byte) (Delta, error) {
+	var delta Delta
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return Delta{}, err
+	}
+	if len(delta.Updates) == 0 {
+		return Delta{}, ErrNotDelta
+	}
+	return delta, nil
+}
+
+// NewValue builds a Value, encoding v as JSON
+func NewValue(path string, v any) Value {
+	raw, _ := json.Marshal(v)
+	return Value{Path: path, Value: raw}
+}
+
+// Translate turns the values of a delta into NMEA sentences: position into
+// RMC, heading into HDT/HDM, speed and course into VTG, depth into DPT, water
+// temperature into MTW and apparent wind into MWV. Unknown paths are skipped.
+func Translate(delta Delta) []nmea.Sentence {
+	var sentences []nmea.Sentence
+
+	for _, update := range delta.Updates {
+		timestamp := update.Timestamp.UTC()
+		var vtg nmea.VTG
+		var mwv nmea.MWV
+
+		for _, value := range update.Values {
+			switch value.Path {
+			case PathPosition:
+				var position Position
+				if json.Unmarshal(value.Value, &position) != nil {
+					continue
+				}
+				sentences = append(sentences, nmea.RMC{
+					DateTime:    timestamp,
+					HasDateTime: !timestamp.IsZero(),
+					Valid:       true,
+					Latitude:    position.Latitude,
+					Longitude:   position.Longitude,
+					HasPosition: true,
+					Mode:        "A",
+				})
+			case PathHeadingTrue:
+				if radians, ok := number(value); ok {
+					sentences = append(sentences, nmea.HDT{Heading: degrees(radians), HasHeading: true})
+				}
+			case PathHeadingMagnetic:
+				if radians, ok := number(value); ok {
+					sentences = append(sentences, nmea.HDM{Heading: degrees(radians), HasHeading: true})
+				}
+			case PathSpeedOverGround:
+				if speed, ok := number(value); ok {
+					vtg.SpeedKnots, vtg.HasSpeed = speed*msToKnots, true
+				}
+			case PathCourseOverGround:
+				if radians, ok := number(value); ok {
+					vtg.TrueTrack, vtg.HasTrueTrack = degrees(radians), true
+				}
+			case PathDepthTransducer:
+				if depth, ok := number(value); ok {
+					sentences = append(sentences, nmea.DPT{Depth: depth, HasDepth: true})
+				}
+			case PathWaterTemperature:
+				if kelvin, ok := number(value); ok {
+					sentences = append(sentences, nmea.MTW{Temperature: kelvin - kelvinOffset, HasTemperature: true})
+				}
+			case PathWindSpeed:
+				if speed, ok := number(value); ok {
+					mwv.SpeedKnots, mwv.HasSpeed = speed*msToKnots, true
+				}
+			case PathWindAngle:
+				if radians, ok := number(value); ok {
+					mwv.Angle, mwv.HasAngle = nmea.NormalizeDegrees(degrees(radians)), true
+				}
+			}
+		}
+
+		if vtg.HasSpeed || vtg.HasTrueTrack {
+			sentences = append(sentences, vtg)
+		}
+		if mwv.HasSpeed || mwv.HasAngle {
+			mwv.Reference, mwv.Valid = "R", true
+			sentences = append(sentences, mwv)
+		}
+	}
+
+	return sentences
+}
+
+// number decodes a numeric value; null values are reported as missing
+func number(value Value) (float64, bool) {
+	var f *float64
+	if json.Unmarshal(value.Value, &f) != nil || f == nil {
+		return 0, false
+	}
+	return *f, true
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// Radians converts degrees to the radians Signal K uses for angles
+func Radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// MetresPerSecond converts knots to the m/s Signal K uses for speeds
+func MetresPerSecond(knots float64) float64 {
+	return knots / msToKnots
+}
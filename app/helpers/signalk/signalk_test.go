@@ -0,0 +1,96 @@
+package signalk
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"goravel/app/helpers/nmea"
+)
+
+func TestTranslate(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	sentences := Translate(Delta{Updates: []Update{{
+		Timestamp: timestamp,
+		Values: []Value{
+			NewValue(PathPosition, Position{Latitude: -6.1, Longitude: 106.8}),
+			NewValue(PathHeadingTrue, Radians(90)),
+			NewValue(PathSpeedOverGround, MetresPerSecond(10)),
+			NewValue(PathCourseOverGround, Radians(45)),
+			NewValue(PathDepthTransducer, 12.5),
+			NewValue("navigation.unknown", 1),
+		},
+	}}})
+
+	if len(sentences) != 4 {
+		t.Fatalf("got %d sentences, want 4: %+v", len(sentences), sentences)
+	}
+
+	rmc := sentences[0].(nmea.RMC)
+	if !rmc.HasPosition || rmc.Latitude != -6.1 || rmc.Longitude != 106.8 || !rmc.DateTime.Equal(timestamp) {
+		t.Errorf("rmc = %+v", rmc)
+	}
+	if hdt := sentences[1].(nmea.HDT); math.Abs(hdt.Heading-90) > 1e-9 {
+		t.Errorf("heading = %v", hdt.Heading)
+	}
+	if dpt := sentences[2].(nmea.DPT); dpt.Depth != 12.5 {
+		t.Errorf("depth = %v", dpt.Depth)
+	}
+	vtg := sentences[3].(nmea.VTG)
+	if math.Abs(vtg.SpeedKnots-10) > 1e-9 || math.Abs(vtg.TrueTrack-45) > 1e-9 {
+		t.Errorf("vtg = %+v", vtg)
+	}
+}
+
+func TestStreamFromStandInServer(t *testing.T) {
+	server, err := NewStandInServer()
+	if err != nil {
+		t.Fatalf("start stand-in server: %v", err)
+	}
+	defer server.Close()
+
+	streams := map[string]string{
+		"websocket": server.WebSocketURL(),
+		"tcp":       server.TCPURL(),
+	}
+	for name, url := range streams {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			received := make(chan Delta, 4)
+			done := make(chan error, 1)
+			go func() {
+				done <- Stream(ctx, url, func(delta Delta) { received <- delta })
+			}()
+
+			for server.Clients() == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// Deltas about other vessels are ignored
+			server.Publish(Delta{Context: "vessels.urn:mrn:imo:mmsi:123456789", Updates: []Update{{
+				Values: []Value{NewValue(PathDepthTransducer, 1.0)},
+			}}})
+			server.Publish(Delta{Context: server.Self, Updates: []Update{{
+				Values: []Value{NewValue(PathDepthTransducer, 7.0)},
+			}}})
+
+			select {
+			case delta := <-received:
+				if depth := Translate(delta)[0].(nmea.DPT).Depth; depth != 7 {
+					t.Errorf("depth = %v, want 7", depth)
+				}
+			case <-ctx.Done():
+				t.Fatal("no delta received")
+			}
+
+			cancel()
+			<-done
+			for server.Clients() != 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
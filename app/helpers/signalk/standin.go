@@ -0,0 +1,196 @@
+package signalk
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// StandInServer is a minimal local Signal K server for tests and bench
+// setups. It serves the WebSocket stream and the TCP stream and sends every
+// published delta to all connected clients.
+type StandInServer struct {
+	Self string
+
+	httpListener net.Listener
+	tcpListener  net.Listener
+	server       *http.Server
+
+	mutex   sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+// NewStandInServer starts a stand-in server on random local ports
+func NewStandInServer() (*StandInServer, error) {
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		httpListener.Close()
+		return nil, err
+	}
+
+	s := &StandInServer{
+		Self:         "vessels.urn:mrn:signalk:uuid:stand-in",
+		httpListener: httpListener,
+		tcpListener:  tcpListener,
+		clients:      make(map[chan []byte]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signalk/v1/stream", s.serveWebSocket)
+	s.server = &http.Server{Handler: mux}
+
+	go s.server.Serve(httpListener)
+	go s.acceptTCP()
+	return s, nil
+}
+
+// WebSocketURL returns the ws:// URL of the stream
+func (s *StandInServer) WebSocketURL() string {
+	return "ws://" + s.httpListener.Addr().String() + "/signalk/v1/stream"
+}
+
+// TCPURL returns the tcp:// URL of the stream
+func (s *StandInServer) TCPURL() string {
+	return "tcp://" + s.tcpListener.Addr().String()
+}
+
+// Publish sends a delta to every connected client
+func (s *StandInServer) Publish(delta Delta) error {
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default: // Slow client, drop the delta
+		}
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients
+func (s *StandInServer) Clients() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+// Close stops both listeners
+func (s *StandInServer) Close() error {
+	s.tcpListener.Close()
+	return s.server.Close()
+}
+
+func (s *StandInServer) hello() []byte {
+	data, _ := json.Marshal(Hello{Name: "stand-in", Version: "2.0.0", Self: s.Self})
+	return data
+}
+
+func (s *StandInServer) subscribe() chan []byte {
+	client := make(chan []byte, 64)
+	s.mutex.Lock()
+	s.clients[client] = struct{}{}
+	s.mutex.Unlock()
+	return client
+}
+
+func (s *StandInServer) unsubscribe(client chan []byte) {
+	s.mutex.Lock()
+	delete(s.clients, client)
+	s.mutex.Unlock()
+}
+
+func (s *StandInServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	client := s.subscribe()
+	defer s.unsubscribe(client)
+
+	// Notice the client going away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if conn.WriteMessage(websocket.TextMessage, s.hello()) != nil {
+		return
+	}
+	for {
+		select {
+		case <-closed:
+			return
+		case data := <-client:
+			if conn.WriteMessage(websocket.TextMessage, data) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *StandInServer) acceptTCP() {
+	for {
+		conn, err := s.tcpListener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveTCP(conn)
+	}
+}
+
+func (s *StandInServer) serveTCP(conn net.Conn) {
+	defer conn.Close()
+
+	client := s.subscribe()
+	defer s.unsubscribe(client)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+		}
+	}()
+
+	writer := bufio.NewWriter(conn)
+	write := func(data []byte) bool {
+		writer.Write(data)
+		writer.WriteByte('\n')
+		return writer.Flush() == nil
+	}
+
+	if !write(s.hello()) {
+		return
+	}
+	for {
+		select {
+		case <-closed:
+			return
+		case data := <-client:
+			if !write(data) {
+				return
+			}
+		}
+	}
+}
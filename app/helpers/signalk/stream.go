@@ -0,0 +1,119 @@
+package signalk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize bounds a single stream message
+const maxMessageSize = 1 << 20
+
+// Stream connects to a Signal K server and calls handle for every delta
+// about the server's own vessel until ctx is done or the connection drops.
+// URLs are ws:// or wss:// for the WebSocket stream and tcp://host:port for
+// the newline delimited TCP stream.
+func Stream(ctx context.Context, rawURL string, handle func(Delta)) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "ws", "wss":
+		return streamWebSocket(ctx, u, handle)
+	case "tcp":
+		return streamTCP(ctx, u.Host, handle)
+	default:
+		return fmt.Errorf("signalk: unsupported scheme %q", u.Scheme)
+	}
+}
+
+func streamWebSocket(ctx context.Context, u *url.URL, handle func(Delta)) error {
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/signalk/v1/stream"
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxMessageSize)
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	reader := &deltaReader{handle: handle}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		reader.read(data)
+	}
+}
+
+func streamTCP(ctx context.Context, address string, handle func(Delta)) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	reader := &deltaReader{handle: handle}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		reader.read(scanner.Bytes())
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("signalk: stream closed by server")
+}
+
+// deltaReader remembers the self context announced in the hello message and
+// passes on deltas about that vessel only
+type deltaReader struct {
+	self   string
+	handle func(Delta)
+}
+
+func (r *deltaReader) read(data []byte) {
+	delta, err := Decode(data)
+	if errors.Is(err, ErrNotDelta) {
+		var hello Hello
+		if json.Unmarshal(data, &hello) == nil && hello.Self != "" {
+			r.self = hello.Self
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	if delta.Context != "" && delta.Context != "vessels.self" && r.self != "" &&
+		strings.TrimPrefix(delta.Context, "vessels.") != strings.TrimPrefix(r.self, "vessels.") {
+		return
+	}
+	r.handle(delta)
+}
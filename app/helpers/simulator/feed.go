@@ -0,0 +1,83 @@
+package simulator
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	minReconnectDelay = 2 * time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Feed connects to address like a device would, over TLS when tlsConfig is
+// set, sends handshake (if any) and then writes the lines returned by next
+// every interval until ctx ends. Dropped connections are redialled with
+// backoff. onError, if set, is told about each failed connection.
+func Feed(ctx context.Context, address string, tlsConfig *tls.Config, handshake string, interval time.Duration, next func(now time.Time) []string, onError func(error)) {
+	delay := minReconnectDelay
+	for {
+		err := feedConnection(ctx, address, tlsConfig, handshake, interval, next)
+		if ctx.Err() != nil {
+			return
+		}
+		if onError != nil && err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func feedConnection(ctx context.Context, address string, tlsConfig *tls.Config, handshake string, interval time.Duration, next func(now time.Time) []string) error {
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		dialer := tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Drain echoes and ACK lines so the listener never blocks on writing
+	go io.Copy(io.Discard, conn)
+
+	if handshake != "" {
+		if _, err := io.WriteString(conn, handshake+"\n"); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			lines := next(now)
+			if len(lines) == 0 {
+				continue
+			}
+			if _, err := io.WriteString(conn, strings.Join(lines, "\n")+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+}
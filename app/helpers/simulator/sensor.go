@@ -0,0 +1,75 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// tidalPeriod is the principal lunar semi-diurnal period
+const tidalPeriod = 12*time.Hour + 25*time.Minute
+
+// Sensor is a synthetic sensor station producing "ID:n KEY:value ... TS:..."
+// messages for its types
+type Sensor struct {
+	ID    string
+	Types []string
+
+	phase float64
+	rand  *rand.Rand
+}
+
+// NewSensor creates a sensor of the given types (tide, weather, water,
+// current, pollution). The seed makes runs repeatable.
+func NewSensor(id string, types []string, seed int64) *Sensor {
+	r := rand.New(rand.NewSource(seed))
+	return &Sensor{ID: id, Types: types, phase: r.Float64() * 2 * math.Pi, rand: r}
+}
+
+// Message returns a reading taken at now
+func (s *Sensor) Message(now time.Time) string {
+	fields := []string{"ID:" + s.ID}
+	for _, sensorType := range s.Types {
+		fields = append(fields, s.readings(sensorType, now)...)
+	}
+	fields = append(fields, "TS:"+now.UTC().Format("2006-01-02 15:04:05"))
+	return strings.Join(fields, " ")
+}
+
+func (s *Sensor) readings(sensorType string, now time.Time) []string {
+	tide := math.Sin(2*math.Pi*float64(now.UnixNano())/float64(tidalPeriod) + s.phase)
+	noise := func(scale float64) float64 { return (s.rand.Float64()*2 - 1) * scale }
+
+	switch sensorType {
+	case "tide":
+		return []string{fmt.Sprintf("LEVEL:%.2f", 1.5+1.2*tide+noise(0.02))}
+	case "weather":
+		return []string{
+			fmt.Sprintf("AIR_TEMP:%.1f", 28+noise(1.5)),
+			fmt.Sprintf("HUMIDITY:%.0f", 75+noise(10)),
+			fmt.Sprintf("PRESSURE:%.1f", 1010+noise(3)),
+			fmt.Sprintf("WIND_SPEED:%.1f", math.Abs(8+noise(4))),
+			fmt.Sprintf("WIND_DIR:%.0f", math.Mod(180+noise(60)+360, 360)),
+		}
+	case "water":
+		return []string{
+			fmt.Sprintf("WATER_TEMP:%.1f", 29+noise(0.5)),
+			fmt.Sprintf("SALINITY:%.1f", 33+noise(0.5)),
+		}
+	case "current":
+		// Flood and ebb follow the tide
+		direction := 45.0
+		if tide < 0 {
+			direction = 225
+		}
+		return []string{
+			fmt.Sprintf("CURRENT_SPEED:%.2f", math.Abs(tide)*1.5+noise(0.05)),
+			fmt.Sprintf("CURRENT_DIR:%.0f", direction+noise(10)),
+		}
+	case "pollution":
+		return []string{fmt.Sprintf("PM25:%.1f", math.Abs(20+noise(8)))}
+	}
+	return nil
+}
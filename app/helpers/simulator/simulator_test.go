@@ -0,0 +1,47 @@
+package simulator
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"goravel/app/helpers/nmea"
+)
+
+func TestVesselFollowsRoute(t *testing.T) {
+	start := Waypoint{Latitude: -1.27, Longitude: 116.80}
+	target := Waypoint{Latitude: -1.26, Longitude: 116.80} // About 0.6 NM north
+	vessel := NewVessel("SIM001", start, []Waypoint{target, start}, 1)
+
+	for i := 0; i < 600; i++ {
+		vessel.Step(time.Second)
+		if distanceMetres(vessel.Latitude, vessel.Longitude, target.Latitude, target.Longitude) < arrivalRadiusMetres {
+			return
+		}
+	}
+	t.Fatalf("vessel did not reach the waypoint, ended at %f, %f", vessel.Latitude, vessel.Longitude)
+}
+
+func TestVesselSentencesParse(t *testing.T) {
+	vessel := NewVessel("SIM001", Waypoint{Latitude: -1.27, Longitude: 116.80}, nil, 2)
+	vessel.Step(time.Second)
+
+	for _, raw := range vessel.Sentences(time.Now()) {
+		sentence, err := nmea.Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", raw, err)
+		}
+		if gga, ok := sentence.(nmea.GGA); ok && (!gga.HasPosition || gga.Latitude > -1.2) {
+			t.Errorf("gga = %+v", gga)
+		}
+	}
+}
+
+func TestSensorMessage(t *testing.T) {
+	sensor := NewSensor("9001", []string{"tide", "weather"}, 3)
+	message := sensor.Message(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+
+	if !regexp.MustCompile(`^ID:9001 LEVEL:-?\d+\.\d{2} AIR_TEMP:.* TS:2025-03-01 10:00:00$`).MatchString(message) {
+		t.Errorf("message = %q", message)
+	}
+}
@@ -0,0 +1,140 @@
+// Package simulator generates synthetic vessel and sensor traffic and feeds
+// it to the TCP listeners the way real devices do.
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"goravel/app/helpers/nmea"
+)
+
+const (
+	metresPerNauticalMile = 1852.0
+	arrivalRadiusMetres   = 50.0
+	maxTurnRate           = 3.0 // Degrees per second
+	wanderRadiusNM        = 10.0
+)
+
+// Waypoint is a position in decimal degrees
+type Waypoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Vessel is a synthetic vessel that follows a route of waypoints, looping
+// back to the first, or wanders around its start when it has no route
+type Vessel struct {
+	CallSign    string
+	Latitude    float64
+	Longitude   float64
+	Course      float64 // Course over ground, true degrees
+	Heading     float64 // Where the bow points, true degrees
+	SpeedKnots  float64
+	DepthMeters float64
+
+	origin      Waypoint
+	route       []Waypoint
+	next        int
+	cruiseSpeed float64
+	rand        *rand.Rand
+}
+
+// NewVessel places a vessel at start. The seed makes runs repeatable.
+func NewVessel(callSign string, start Waypoint, route []Waypoint, seed int64) *Vessel {
+	r := rand.New(rand.NewSource(seed))
+	v := &Vessel{
+		CallSign:    callSign,
+		Latitude:    start.Latitude,
+		Longitude:   start.Longitude,
+		Course:      r.Float64() * 360,
+		DepthMeters: 10 + r.Float64()*40,
+		origin:      start,
+		route:       route,
+		cruiseSpeed: 6 + r.Float64()*8,
+		rand:        r,
+	}
+	v.SpeedKnots = v.cruiseSpeed
+	v.Heading = v.Course
+	return v
+}
+
+// Step moves the vessel along for dt
+func (v *Vessel) Step(dt time.Duration) {
+	seconds := dt.Seconds()
+	if seconds <= 0 {
+		return
+	}
+
+	target := v.Course
+	if len(v.route) > 0 {
+		waypoint := v.route[v.next]
+		if distanceMetres(v.Latitude, v.Longitude, waypoint.Latitude, waypoint.Longitude) < arrivalRadiusMetres {
+			v.next = (v.next + 1) % len(v.route)
+			waypoint = v.route[v.next]
+		}
+		target = bearing(v.Latitude, v.Longitude, waypoint.Latitude, waypoint.Longitude)
+	} else {
+		target += (v.rand.Float64()*2 - 1) * 4 * seconds
+		// Turn back before wandering off the area
+		if distanceMetres(v.Latitude, v.Longitude, v.origin.Latitude, v.origin.Longitude) > wanderRadiusNM*metresPerNauticalMile {
+			target = bearing(v.Latitude, v.Longitude, v.origin.Latitude, v.origin.Longitude)
+		}
+	}
+
+	turn := math.Max(-maxTurnRate*seconds, math.Min(maxTurnRate*seconds, angleDifference(target, v.Course)))
+	v.Course = nmea.NormalizeDegrees(v.Course + turn)
+	v.Heading = nmea.NormalizeDegrees(v.Course + (v.rand.Float64()*2-1)*2) // Yaw and set
+
+	v.SpeedKnots += (v.cruiseSpeed-v.SpeedKnots)*0.1 + (v.rand.Float64()*2-1)*0.2
+	v.SpeedKnots = math.Max(0, v.SpeedKnots)
+	v.DepthMeters = math.Max(2, v.DepthMeters+(v.rand.Float64()*2-1)*0.5*seconds)
+
+	distanceNM := v.SpeedKnots * seconds / 3600
+	radians := v.Course * math.Pi / 180
+	v.Latitude += distanceNM * math.Cos(radians) / 60
+	v.Longitude += distanceNM * math.Sin(radians) / (60 * math.Cos(v.Latitude*math.Pi/180))
+}
+
+// Sentences returns GGA, HDT, VTG and DBT sentences for the current state
+func (v *Vessel) Sentences(now time.Time) []string {
+	lat, ns := nmea.FormatLatitude(v.Latitude)
+	lon, ew := nmea.FormatLongitude(v.Longitude)
+	depthFeet := v.DepthMeters * 3.28084
+
+	return []string{
+		nmea.Encode("GP", nmea.TypeGGA, nmea.FormatTime(now), lat, ns, lon, ew, "1", "10", "0.9", "0.0", "M", "0.0", "M", "", ""),
+		nmea.Encode("HE", nmea.TypeHDT, fmt.Sprintf("%.1f", v.Heading), "T"),
+		nmea.Encode("GP", nmea.TypeVTG, fmt.Sprintf("%.1f", v.Course), "T", "", "M", fmt.Sprintf("%.1f", v.SpeedKnots), "N", fmt.Sprintf("%.1f", v.SpeedKnots*1.852), "K", "A"),
+		nmea.Encode("SD", nmea.TypeDBT, fmt.Sprintf("%.1f", depthFeet), "f", fmt.Sprintf("%.1f", v.DepthMeters), "M", fmt.Sprintf("%.1f", v.DepthMeters/1.8288), "F"),
+	}
+}
+
+// distanceMetres is the haversine distance between two positions
+func distanceMetres(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi, dLambda := (lat2-lat1)*math.Pi/180, (lon2-lon1)*math.Pi/180
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// bearing is the initial true bearing from the first position to the second
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return nmea.NormalizeDegrees(math.Atan2(y, x) * 180 / math.Pi)
+}
+
+// angleDifference returns the signed turn from b to a in (-180, 180]
+func angleDifference(a, b float64) float64 {
+	diff := math.Mod(a-b+540, 360) - 180
+	if diff == -180 {
+		return 180
+	}
+	return diff
+}
@@ -0,0 +1,91 @@
+// Package sounding reads the output of echosounders: NMEA DBT, DPT and DBS
+// sentences, and the plain ASCII depth lines many sounders print instead.
+package sounding
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"goravel/app/helpers/nmea"
+)
+
+// Sources of a reading besides the NMEA sentence types
+const SourceASCII = "ASCII"
+
+var ErrNoDepth = errors.New("no depth in line")
+
+// Reading is one measured depth
+type Reading struct {
+	DepthMeters  float64 // Below the transducer, or below the surface for DBS
+	OffsetMeters float64 // DPT transducer offset; positive to the waterline, negative to the keel
+	Source       string  // DBT, DPT, DBS or ASCII
+}
+
+// BelowSurface returns the depth below the water surface when the
+// transducer offset to the waterline is known
+func (r Reading) BelowSurface() float64 {
+	if r.OffsetMeters > 0 {
+		return r.DepthMeters + r.OffsetMeters
+	}
+	return r.DepthMeters
+}
+
+// asciiDepth matches "12.34", "12.34 m", "DEPTH 40.5ft", "D=6.2 fm" and the
+// like: an optional label, a number and an optional unit (metres by default)
+var asciiDepth = regexp.MustCompile(`^(?:[A-Za-z][A-Za-z0-9_ ]*?\s*[:=]?\s*)?(\d+(?:\.\d+)?)\s*(m|ft|fm)?$`)
+
+// Parse reads a depth from one line of sounder output. parser validates NMEA
+// sentences; nil uses the default parser.
+func Parse(line string, parser *nmea.Parser) (Reading, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Reading{}, ErrNoDepth
+	}
+
+	if line[0] == '$' {
+		if parser == nil {
+			parser = &nmea.Parser{}
+		}
+		sentence, err := parser.Parse(line)
+		if err != nil {
+			return Reading{}, err
+		}
+		return fromSentence(sentence)
+	}
+
+	matches := asciiDepth.FindStringSubmatch(strings.ToLower(line))
+	if matches == nil {
+		return Reading{}, ErrNoDepth
+	}
+	depth, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return Reading{}, ErrNoDepth
+	}
+	switch matches[2] {
+	case "ft":
+		depth *= 0.3048
+	case "fm":
+		depth *= 1.8288
+	}
+	return Reading{DepthMeters: depth, Source: SourceASCII}, nil
+}
+
+func fromSentence(sentence nmea.Sentence) (Reading, error) {
+	switch s := sentence.(type) {
+	case nmea.DBT:
+		if s.HasDepth {
+			return Reading{DepthMeters: s.Meters(), Source: nmea.TypeDBT}, nil
+		}
+	case nmea.DBS:
+		if s.HasDepth {
+			return Reading{DepthMeters: s.Meters(), Source: nmea.TypeDBS}, nil
+		}
+	case nmea.DPT:
+		if s.HasDepth {
+			return Reading{DepthMeters: s.Depth, OffsetMeters: s.Offset, Source: nmea.TypeDPT}, nil
+		}
+	}
+	return Reading{}, ErrNoDepth
+}
@@ -0,0 +1,47 @@
+package sounding
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line   string
+		depth  float64
+		offset float64
+		source string
+	}{
+		{"$SDDBT,36.1,f,11.0,M,6.0,F*04", 11.0, 0, "DBT"},
+		{"$SDDPT,11.0,0.5*62", 11.0, 0.5, "DPT"},
+		{"$SDDBS,39.4,f,12.0,M,6.6,F*0C", 12.0, 0, "DBS"},
+		{"12.34", 12.34, 0, SourceASCII},
+		{"DEPTH: 40ft", 12.192, 0, SourceASCII},
+		{"D=2 fm", 3.6576, 0, SourceASCII},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			reading, err := Parse(tt.line, nil)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.line, err)
+			}
+			if math.Abs(reading.DepthMeters-tt.depth) > 1e-9 || reading.OffsetMeters != tt.offset || reading.Source != tt.source {
+				t.Errorf("Parse(%q) = %+v", tt.line, reading)
+			}
+		})
+	}
+}
+
+func TestParseRejectsOtherOutput(t *testing.T) {
+	for _, line := range []string{"", "ALARM SHALLOW WATER", "$GPHDT,274.07,T*03", "$SDDBT,,f,,M,,F*28"} {
+		if _, err := Parse(line, nil); err == nil {
+			t.Errorf("Parse(%q) accepted", line)
+		}
+	}
+
+	if _, err := Parse("$SDDBT,36.1,f,11.0,M,6.0,F*00", nil); errors.Is(err, ErrNoDepth) {
+		t.Errorf("checksum failure reported as %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+)
+
+type AisContactController struct {
+	// Dependent services
+}
+
+func NewAisContactController() *AisContactController {
+	return &AisContactController{
+		// Inject services
+	}
+}
+
+// Index returns a list of AIS contacts
+// @Summary Get AIS contacts
+// @Description Get a list of third-party vessels seen over AIS
+// @Tags AIS
+// @Accept json
+// @Produce json
+// @Param active_within query int false "Only contacts seen within this many minutes"
+// @Param search query string false "Search by name, call sign or MMSI"
+// @Param received_by query string false "Call sign of the relaying vessel"
+// @Success 200 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/ais [get]
+func (c *AisContactController) Index(ctx http.Context) http.Response {
+	var contacts []models.AisContact
+
+	// Get query parameters for pagination
+	page, _ := strconv.Atoi(ctx.Request().Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Request().Query("limit", "50"))
+
+	// Initialize query builder
+	query := facades.Orm().Query()
+
+	// Apply filters if provided
+	if activeWithin, err := strconv.Atoi(ctx.Request().Query("active_within", "")); err == nil && activeWithin > 0 {
+		query = query.Where("last_seen_at > ?", time.Now().Add(-time.Duration(activeWithin)*time.Minute))
+	}
+
+	if search := ctx.Request().Query("search", ""); search != "" {
+		query = query.Where("(name LIKE ? OR call_sign LIKE ? OR mmsi LIKE ?)", "%"+search+"%", "%"+search+"%", search+"%")
+	}
+
+	if receivedBy := ctx.Request().Query("received_by", ""); receivedBy != "" {
+		query = query.Where("received_by", receivedBy)
+	}
+
+	// Get total count for pagination
+	var total int64
+	if err := query.Model(&models.AisContact{}).Count(&total); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to count AIS contacts",
+			"error":   err.Error(),
+		})
+	}
+
+	// Execute the paginated query
+	offset := (page - 1) * limit
+	if err := query.Model(&models.AisContact{}).Order("last_seen_at DESC").Offset(offset).Limit(limit).Find(&contacts); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve AIS contacts",
+			"error":   err.Error(),
+		})
+	}
+
+	// Return response with pagination info
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": contacts,
+		"meta": http.Json{
+			"current_page": page,
+			"per_page":     limit,
+			"total":        total,
+			"last_page":    (int(total) + limit - 1) / limit,
+		},
+	})
+}
+
+// Show returns a single AIS contact by MMSI
+// @Summary Get an AIS contact
+// @Description Get an AIS contact by MMSI
+// @Tags AIS
+// @Accept json
+// @Produce json
+// @Param mmsi path int true "MMSI"
+// @Success 200 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/ais/{mmsi} [get]
+func (c *AisContactController) Show(ctx http.Context) http.Response {
+	mmsi, err := strconv.ParseUint(ctx.Request().Route("mmsi"), 10, 32)
+	if err != nil {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "Invalid MMSI",
+		})
+	}
+
+	var contact models.AisContact
+	if err := facades.Orm().Query().Where("mmsi", mmsi).FirstOrFail(&contact); err != nil {
+		if err.Error() == "record not found" {
+			return ctx.Response().Json(http.StatusNotFound, http.Json{
+				"message": "AIS contact not found",
+			})
+		}
+
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve AIS contact",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": contact,
+	})
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/goravel/framework/facades"
 
 	// "goravel/app/http/requests"
+	"goravel/app/helpers/secret"
 	"goravel/app/models"
+	"goravel/app/services"
 )
 
 type KapalController struct {
@@ -196,6 +198,14 @@ func (c *KapalController) Show(ctx http.Context) http.Response {
 // @Param minimum_knot_per_liter_gasoline formData float true "Minimum Knot Per Liter Gasoline"
 // @Param maximum_knot_per_liter_gasoline formData float true "Maximum Knot Per Liter Gasoline"
 // @Param record_status formData bool true "Record Status"
+// @Param ack_mode formData string false "Reply to each TCP line with none, echo or ack; empty uses the listener default"
+// @Param source_priority formData string false "Sources most preferred first, e.g. telnet:3,tcp,udp,ip:5"
+// @Param max_speed_knots formData float false "Fastest plausible speed between two fixes; 0 uses the filter default"
+// @Param record_mode formData string false "interval or adaptive; empty uses the configured mode"
+// @Param deadband_meters formData float false "Adaptive mode: record after moving this far; 0 uses the default"
+// @Param deadband_degrees formData float false "Adaptive mode: record after turning this much; 0 uses the default"
+// @Param deadband_knots formData float false "Adaptive mode: record after a speed change this large; 0 uses the default"
+// @Param max_record_interval formData int false "Adaptive mode: seconds after which a record is stored anyway; 0 uses the default"
 // @Param image_file formData file false "Vessel Image"
 // @Param image_map_file formData file false "Vessel Map Image"
 // @Success 201 {object} http.Response
@@ -226,6 +236,27 @@ func (c *KapalController) Store(ctx http.Context) http.Response {
 		})
 	}
 
+	ackMode := ctx.Request().Input("ack_mode")
+	if ackMode != "" && !services.ValidAckMode(ackMode) {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "ack_mode must be none, echo or ack",
+		})
+	}
+
+	sourcePriority := ctx.Request().Input("source_priority")
+	if _, invalid := services.ParseSourcePriority(sourcePriority); invalid != "" {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "source_priority has an unknown source: " + invalid,
+		})
+	}
+
+	recordMode := ctx.Request().Input("record_mode")
+	if recordMode != "" && !services.ValidRecordMode(recordMode) {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "record_mode must be interval or adaptive",
+		})
+	}
+
 	// Create new vessel
 	kapal := models.Kapal{
 		CallSign:                    callSign,
@@ -243,6 +274,14 @@ func (c *KapalController) Store(ctx http.Context) http.Response {
 		MinimumKnotPerLiterGasoline: c.parseFloatField(ctx, "minimum_knot_per_liter_gasoline"),
 		MaximumKnotPerLiterGasoline: c.parseFloatField(ctx, "maximum_knot_per_liter_gasoline"),
 		RecordStatus:                ctx.Request().Input("record_status") == "true",
+		AckMode:                     ackMode,
+		SourcePriority:              sourcePriority,
+		MaxSpeedKnots:               c.parseFloatField(ctx, "max_speed_knots"),
+		RecordMode:                  recordMode,
+		DeadbandMeters:              c.parseFloatField(ctx, "deadband_meters"),
+		DeadbandDegrees:             c.parseFloatField(ctx, "deadband_degrees"),
+		DeadbandKnots:               c.parseFloatField(ctx, "deadband_knots"),
+		MaxRecordInterval:           int64(c.parseIntField(ctx, "max_record_interval")),
 		CreatedAt:                   time.Now(),
 		UpdatedAt:                   time.Now(),
 	}
@@ -328,6 +367,14 @@ func (c *KapalController) Store(ctx http.Context) http.Response {
 // @Param minimum_knot_per_liter_gasoline formData float false "Minimum Knot Per Liter Gasoline"
 // @Param maximum_knot_per_liter_gasoline formData float false "Maximum Knot Per Liter Gasoline"
 // @Param record_status formData bool false "Record Status"
+// @Param ack_mode formData string false "none, echo or ack; default clears it"
+// @Param source_priority formData string false "Sources most preferred first, e.g. telnet:3,tcp; default clears it"
+// @Param max_speed_knots formData float false "Fastest plausible speed between two fixes; a negative value uses the filter default"
+// @Param record_mode formData string false "interval or adaptive; default clears it"
+// @Param deadband_meters formData float false "Adaptive mode distance threshold; a negative value uses the default"
+// @Param deadband_degrees formData float false "Adaptive mode course threshold; a negative value uses the default"
+// @Param deadband_knots formData float false "Adaptive mode speed threshold; a negative value uses the default"
+// @Param max_record_interval formData int false "Adaptive mode maximum seconds between records; a negative value uses the default"
 // @Param image_file formData file false "Vessel Image"
 // @Param image_map_file formData file false "Vessel Map Image"
 // @Param remove_image formData bool false "Remove vessel image"
@@ -402,6 +449,70 @@ func (c *KapalController) Update(ctx http.Context) http.Response {
 		kapal.RecordStatus = recordStatus == "true"
 	}
 
+	// Update the acknowledgement mode if provided, "default" falls back to the listener's
+	if ackMode := ctx.Request().Input("ack_mode"); ackMode == "default" {
+		kapal.AckMode = ""
+	} else if ackMode != "" {
+		if !services.ValidAckMode(ackMode) {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "ack_mode must be none, echo, ack or default",
+			})
+		}
+		kapal.AckMode = ackMode
+	}
+
+	// Update the plausible maximum speed if provided, a negative value falls back to the filter default
+	if maxSpeed := c.parseFloatField(ctx, "max_speed_knots"); maxSpeed > 0 {
+		kapal.MaxSpeedKnots = maxSpeed
+	} else if maxSpeed < 0 {
+		kapal.MaxSpeedKnots = 0
+	}
+
+	// Update the record mode if provided, "default" falls back to the configured mode
+	if recordMode := ctx.Request().Input("record_mode"); recordMode == "default" {
+		kapal.RecordMode = ""
+	} else if recordMode != "" {
+		if !services.ValidRecordMode(recordMode) {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "record_mode must be interval, adaptive or default",
+			})
+		}
+		kapal.RecordMode = recordMode
+	}
+
+	// Update the adaptive recording thresholds if provided, negative values fall back to the defaults
+	for _, threshold := range []struct {
+		field string
+		value *float64
+	}{
+		{"deadband_meters", &kapal.DeadbandMeters},
+		{"deadband_degrees", &kapal.DeadbandDegrees},
+		{"deadband_knots", &kapal.DeadbandKnots},
+	} {
+		if value := c.parseFloatField(ctx, threshold.field); value > 0 {
+			*threshold.value = value
+		} else if value < 0 {
+			*threshold.value = 0
+		}
+	}
+	if maxInterval := int64(c.parseIntField(ctx, "max_record_interval")); maxInterval > 0 {
+		kapal.MaxRecordInterval = maxInterval
+	} else if maxInterval < 0 {
+		kapal.MaxRecordInterval = 0
+	}
+
+	// Update the source priority if provided, "default" ranks all sources equally
+	if sourcePriority := ctx.Request().Input("source_priority"); sourcePriority == "default" {
+		kapal.SourcePriority = ""
+	} else if sourcePriority != "" {
+		if _, invalid := services.ParseSourcePriority(sourcePriority); invalid != "" {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "source_priority has an unknown source: " + invalid,
+			})
+		}
+		kapal.SourcePriority = sourcePriority
+	}
+
 	// Check if we should remove the vessel image
 	removeImage := ctx.Request().Input("remove_image") == "true"
 	if removeImage && kapal.Image != "" && !strings.HasPrefix(kapal.Image, "http") {
@@ -477,6 +588,7 @@ func (c *KapalController) Update(ctx http.Context) http.Response {
 			"error":   err.Error(),
 		})
 	}
+	forgetCachedKapal(kapal.CallSign)
 
 	// Convert image paths to full URLs for response
 	if kapal.Image != "" && !strings.HasPrefix(kapal.Image, "http") {
@@ -531,6 +643,7 @@ func (c *KapalController) Destroy(ctx http.Context) http.Response {
 			"error":   err.Error(),
 		})
 	}
+	forgetCachedKapal(kapal.CallSign)
 
 	return ctx.Response().Json(http.StatusOK, http.Json{
 		"message": "Vessel deleted successfully",
@@ -577,6 +690,7 @@ func (c *KapalController) Restore(ctx http.Context) http.Response {
 			"error":   err.Error(),
 		})
 	}
+	forgetCachedKapal(kapal.CallSign)
 
 	// Convert image paths to full URLs for response
 	if kapal.Image != "" && !strings.HasPrefix(kapal.Image, "http") {
@@ -592,8 +706,165 @@ func (c *KapalController) Restore(ctx http.Context) http.Response {
 	})
 }
 
+// RotateSecret issues a new device secret for a vessel
+// @Summary Rotate a vessel's device secret
+// @Description Generate a new secret for the navigation TCP handshake. The secret is only returned once; the previous one stops working.
+// @Tags Kapal
+// @Accept json
+// @Produce json
+// @Param call_sign path string true "Call Sign"
+// @Success 200 {object} http.Response
+// @Failure 401 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/kapal/{call_sign}/secret [post]
+func (c *KapalController) RotateSecret(ctx http.Context) http.Response {
+	callSign := ctx.Request().Route("call_sign")
+
+	var kapal models.Kapal
+	if err := facades.Orm().Query().Where("call_sign", callSign).WhereNull("deleted_at").FirstOrFail(&kapal); err != nil {
+		if err.Error() == "record not found" {
+			return ctx.Response().Json(http.StatusNotFound, http.Json{
+				"message": "Vessel not found",
+			})
+		}
+
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve vessel",
+			"error":   err.Error(),
+		})
+	}
+
+	deviceSecret, hash, err := secret.Generate()
+	if err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to generate secret",
+			"error":   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	if _, err := facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).Update(map[string]any{
+		"device_secret_hash": hash,
+		"secret_rotated_at":  now,
+	}); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to save secret",
+			"error":   err.Error(),
+		})
+	}
+	forgetCachedKapal(callSign)
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"message": "Device secret rotated successfully",
+		"data": http.Json{
+			"call_sign":  callSign,
+			"secret":     deviceSecret,
+			"rotated_at": now,
+		},
+	})
+}
+
+// RevokeSecret removes a vessel's device secret
+// @Summary Revoke a vessel's device secret
+// @Description Remove the secret so the vessel can no longer authenticate with it
+// @Tags Kapal
+// @Accept json
+// @Produce json
+// @Param call_sign path string true "Call Sign"
+// @Success 200 {object} http.Response
+// @Failure 401 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/kapal/{call_sign}/secret [delete]
+func (c *KapalController) RevokeSecret(ctx http.Context) http.Response {
+	callSign := ctx.Request().Route("call_sign")
+
+	result, err := facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).WhereNull("deleted_at").Update(map[string]any{
+		"device_secret_hash": "",
+		"secret_rotated_at":  time.Now(),
+	})
+	if err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to revoke secret",
+			"error":   err.Error(),
+		})
+	}
+	if result.RowsAffected == 0 {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "Vessel not found",
+		})
+	}
+	forgetCachedKapal(callSign)
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"message": "Device secret revoked successfully",
+	})
+}
+
+// RotateIPToken issues a new handshake token for one IPKapal entry
+// @Summary Rotate an IP entry token
+// @Description Generate a new handshake token for a single IPKapal entry of the vessel. The token is only returned once.
+// @Tags Kapal
+// @Accept json
+// @Produce json
+// @Param call_sign path string true "Call Sign"
+// @Param id path int true "IPKapal ID"
+// @Success 200 {object} http.Response
+// @Failure 401 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/kapal/{call_sign}/ip/{id}/token [post]
+func (c *KapalController) RotateIPToken(ctx http.Context) http.Response {
+	callSign := ctx.Request().Route("call_sign")
+	id := ctx.Request().RouteInt("id")
+
+	token, hash, err := secret.Generate()
+	if err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to generate token",
+			"error":   err.Error(),
+		})
+	}
+
+	result, err := facades.Orm().Query().Model(&models.IPKapal{}).Where("id", id).Where("call_sign", callSign).Update(map[string]any{
+		"token_hash": hash,
+		"updated_at": time.Now(),
+	})
+	if err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to save token",
+			"error":   err.Error(),
+		})
+	}
+	if result.RowsAffected == 0 {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "IP entry not found",
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"message": "IP token rotated successfully",
+		"data": http.Json{
+			"id":        id,
+			"call_sign": callSign,
+			"token":     token,
+		},
+	})
+}
+
 // Helper functions
 
+// forgetCachedKapal makes the navigation listener reload a vessel, so edited
+// settings, a deletion or a rotated or revoked secret take effect at once
+func forgetCachedKapal(callSign string) {
+	if instance, err := facades.App().Make("tcp_navigation_service"); err == nil {
+		if vessels, ok := instance.(*services.TCPVesselService); ok {
+			vessels.ForgetKapal(callSign)
+		}
+	}
+}
+
 // parseIntField safely parses an integer field from the request
 func (c *KapalController) parseIntField(ctx http.Context, fieldName string) int {
 	value := ctx.Request().Input(fieldName)
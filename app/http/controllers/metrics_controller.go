@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/helpers/metrics"
+)
+
+type MetricsController struct {
+	// Dependent services
+}
+
+func NewMetricsController() *MetricsController {
+	return &MetricsController{
+		// Inject services
+	}
+}
+
+// Index returns the ingest counters and gauges for Prometheus
+// @Summary Get ingest metrics
+// @Description Get sentence, failure, record and sensor message counters and connection, session, client, buffer and write latency gauges in the Prometheus text exposition format
+// @Tags Metrics
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 404 {object} http.Response
+// @Router /metrics [get]
+func (c *MetricsController) Index(ctx http.Context) http.Response {
+	if !facades.Config().GetBool("tcp.metrics.enabled", true) {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "Metrics are disabled",
+		})
+	}
+
+	var body strings.Builder
+	if _, err := metrics.Default.WriteTo(&body); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to write metrics",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Data(http.StatusOK, metrics.ContentType, []byte(body.String()))
+}
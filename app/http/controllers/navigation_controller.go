@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+	"goravel/app/services"
+)
+
+type NavigationController struct {
+	// Dependent services
+}
+
+// RawLogRequest selects a time window of a vessel's raw NMEA archive
+type RawLogRequest struct {
+	StartTime time.Time `form:"start_time" binding:"required"` // UTC time
+	EndTime   time.Time `form:"end_time" binding:"required"`   // UTC time
+}
+
+// maxRawLogWindow bounds a single download, one day is roughly 40 MB of text
+// for a vessel sending at 1 Hz
+const maxRawLogWindow = 7 * 24 * time.Hour
+
+func NewNavigationController() *NavigationController {
+	return &NavigationController{
+		// Inject services
+	}
+}
+
+// UDPSources returns the packet counters of the UDP navigation listener
+// @Summary Get UDP sources
+// @Description Get the packet, line and rejection counts per UDP source address
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/udp/sources [get]
+func (c *NavigationController) UDPSources(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("udp_navigation_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "UDP navigation service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": instance.(*services.UDPVesselService).GetSourceStats(),
+	})
+}
+
+// IPConnections returns the state of the outbound connection of every IPKapal entry
+// @Summary Get IPKapal connections
+// @Description Get the connection state and line counts of the devices polled from IPKapal entries
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/ip/connections [get]
+func (c *NavigationController) IPConnections(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("ip_kapal_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "IPKapal polling service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	service := instance.(*services.IPKapalService)
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"enabled": service.Enabled(),
+		"data":    service.GetConnectionStates(),
+	})
+}
+
+// TelnetSessions returns the health of every telnet session
+// @Summary Get telnet session states
+// @Description Get the state (connecting, connected, backoff, disabled), last error, last data time and counters of each telnet session
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/telnet/sessions [get]
+func (c *NavigationController) TelnetSessions(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("telnet_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Telnet service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": instance.(*services.TelnetService).GetSessionStates(),
+	})
+}
+
+// Sources returns the source selected for each role of every live vessel
+// @Summary Get vessel sources
+// @Description Get the selected and candidate sources of the position, heading, speed and depth of each vessel
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/sources [get]
+func (c *NavigationController) Sources(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("tcp_navigation_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Navigation service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": instance.(*services.TCPVesselService).GetSourceStates(),
+	})
+}
+
+// Plausibility returns the accepted and rejected position fixes of every vessel
+// @Summary Get position filter counters
+// @Description Get the number of accepted fixes and of fixes rejected as invalid, too fast or on land, per vessel
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/plausibility [get]
+func (c *NavigationController) Plausibility(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("tcp_navigation_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Navigation service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": instance.(*services.TCPVesselService).GetPositionFilterStats(),
+	})
+}
+
+// RejectedFixes returns the rejected fixes kept for audit, newest first
+// @Summary Get rejected fixes
+// @Description Get the position fixes the plausibility filter rejected, when PLAUSIBILITY_KEEP_REJECTED is set
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Param call_sign query string false "Call Sign"
+// @Param reason query string false "invalid, speed or land"
+// @Success 200 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/navigation/rejected_fixes [get]
+func (c *NavigationController) RejectedFixes(ctx http.Context) http.Response {
+	var fixes []models.RejectedFix
+
+	// Get query parameters for pagination
+	page, _ := strconv.Atoi(ctx.Request().Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Request().Query("limit", "100"))
+
+	// Initialize query builder
+	query := facades.Orm().Query()
+
+	// Apply filters if provided
+	if callSign := ctx.Request().Query("call_sign", ""); callSign != "" {
+		query = query.Where("call_sign", callSign)
+	}
+	if reason := ctx.Request().Query("reason", ""); reason != "" {
+		query = query.Where("reason", reason)
+	}
+
+	// Get total count for pagination
+	var total int64
+	if err := query.Model(&models.RejectedFix{}).Count(&total); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to count rejected fixes",
+			"error":   err.Error(),
+		})
+	}
+
+	// Execute the paginated query
+	offset := (page - 1) * limit
+	if err := query.Model(&models.RejectedFix{}).Order("received_at DESC").Offset(offset).Limit(limit).Find(&fixes); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve rejected fixes",
+			"error":   err.Error(),
+		})
+	}
+
+	// Return response with pagination info
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": fixes,
+		"meta": http.Json{
+			"current_page": page,
+			"per_page":     limit,
+			"total":        total,
+			"last_page":    (int(total) + limit - 1) / limit,
+		},
+	})
+}
+
+// RawLog downloads the raw sentences a vessel sent within a time window, one
+// "<received at>\t<source address>\t<sentence>" line each
+// @Summary Download raw NMEA
+// @Description Download the archived raw NMEA lines of a vessel received between start_time and end_time (at most 7 days)
+// @Tags Navigation
+// @Produce plain
+// @Param call_sign path string true "Call Sign"
+// @Param start_time query string true "Start time (RFC 3339)"
+// @Param end_time query string true "End time (RFC 3339)"
+// @Success 200 {string} string
+// @Failure 400 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/nmea/{call_sign} [get]
+func (c *NavigationController) RawLog(ctx http.Context) http.Response {
+	var request RawLogRequest
+	if err := ctx.Request().Bind(&request); err != nil {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "Invalid request parameters",
+			"error":   err.Error(),
+		})
+	}
+	if request.EndTime.Before(request.StartTime) || request.EndTime.Sub(request.StartTime) > maxRawLogWindow {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "end_time must follow start_time by at most 7 days",
+		})
+	}
+
+	instance, err := facades.App().Make("nmea_archive")
+	if err != nil || !instance.(*services.NMEAArchive).Enabled() {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Raw NMEA archive is not enabled",
+		})
+	}
+
+	callSign := ctx.Request().Route("call_sign")
+	writer := ctx.Response().Writer()
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+		fmt.Sprintf("%s_%s.nmea", callSign, request.StartTime.UTC().Format("20060102T150405Z"))))
+	writer.WriteHeader(http.StatusOK)
+
+	// The status line is already sent, a failure can only cut the download short
+	if err := instance.(*services.NMEAArchive).Export(callSign, request.StartTime, request.EndTime, writer); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to export raw NMEA for %s: %v", callSign, err))
+	}
+
+	return nil
+}
+
+// WriteQueue returns the state of the queues that batch record inserts
+// @Summary Get record write queues
+// @Description Get the depth, capacity, written, retrying and dropped counts and last flush latency of the vessel, telemetry and sensor record queues
+// @Tags Navigation
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 503 {object} http.Response
+// @Router /api/navigation/write_queue [get]
+func (c *NavigationController) WriteQueue(ctx http.Context) http.Response {
+	instance, err := facades.App().Make("record_writer")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Record writer is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	writer := instance.(*services.RecordWriter)
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"enabled": writer.Enabled(),
+		"data":    writer.Stats(),
+	})
+}
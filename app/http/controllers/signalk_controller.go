@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+	"goravel/app/services"
+)
+
+type SignalKController struct {
+	// Dependent services
+}
+
+func NewSignalKController() *SignalKController {
+	return &SignalKController{
+		// Inject services
+	}
+}
+
+// Sources returns the configured Signal K sources with their connection state
+// @Summary Get Signal K sources
+// @Description Get the Signal K servers vessels are streamed from
+// @Tags SignalK
+// @Accept json
+// @Produce json
+// @Param call_sign query string false "Only sources of this vessel"
+// @Success 200 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/signalk/sources [get]
+func (c *SignalKController) Sources(ctx http.Context) http.Response {
+	var sources []models.SignalKSource
+
+	query := facades.Orm().Query()
+	if callSign := ctx.Request().Query("call_sign", ""); callSign != "" {
+		query = query.Where("call_sign", callSign)
+	}
+
+	if err := query.Order("id ASC").Find(&sources); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve Signal K sources",
+			"error":   err.Error(),
+		})
+	}
+
+	statuses := make(map[uint]services.SignalKSourceStatus)
+	if instance, err := facades.App().Make("signalk_service"); err == nil {
+		for _, status := range instance.(*services.SignalKService).GetSourceStatus() {
+			statuses[status.ID] = status
+		}
+	}
+
+	data := make([]http.Json, 0, len(sources))
+	for _, source := range sources {
+		status, running := statuses[source.ID]
+		data = append(data, http.Json{
+			"source":  source,
+			"running": running,
+			"status":  status,
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": data,
+	})
+}
+
+// StoreSource adds a Signal K source to a vessel
+// @Summary Create a Signal K source
+// @Description Stream a vessel's data from a Signal K server. Sources are picked up within a minute.
+// @Tags SignalK
+// @Accept json
+// @Produce json
+// @Param call_sign formData string true "Call Sign"
+// @Param url formData string true "ws://, wss:// or tcp:// stream URL"
+// @Param enabled formData bool false "Enabled, defaults to true"
+// @Success 201 {object} http.Response
+// @Failure 400 {object} http.Response
+// @Failure 401 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/signalk/sources [post]
+func (c *SignalKController) StoreSource(ctx http.Context) http.Response {
+	callSign := ctx.Request().Input("call_sign")
+	streamURL := ctx.Request().Input("url")
+
+	if callSign == "" || streamURL == "" {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "Call sign and url are required",
+		})
+	}
+
+	parsed, err := url.Parse(streamURL)
+	if err != nil || (parsed.Scheme != "ws" && parsed.Scheme != "wss" && parsed.Scheme != "tcp") || parsed.Host == "" {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "URL must be a ws://, wss:// or tcp:// address",
+		})
+	}
+
+	var count int64
+	if err := facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).WhereNull("deleted_at").Count(&count); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to check vessel",
+			"error":   err.Error(),
+		})
+	}
+	if count == 0 {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "Vessel not found",
+		})
+	}
+
+	source := models.SignalKSource{
+		CallSign:  callSign,
+		URL:       streamURL,
+		Enabled:   ctx.Request().Input("enabled", "true") == "true",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := facades.Orm().Query().Create(&source); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to create Signal K source",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusCreated, http.Json{
+		"message": "Signal K source created successfully",
+		"data":    source,
+	})
+}
+
+// DestroySource removes a Signal K source
+// @Summary Delete a Signal K source
+// @Description Stop streaming from a Signal K server and remove it
+// @Tags SignalK
+// @Accept json
+// @Produce json
+// @Param id path int true "Source ID"
+// @Success 200 {object} http.Response
+// @Failure 401 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/signalk/sources/{id} [delete]
+func (c *SignalKController) DestroySource(ctx http.Context) http.Response {
+	result, err := facades.Orm().Query().Where("id", ctx.Request().RouteInt("id")).Delete(&models.SignalKSource{})
+	if err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to delete Signal K source",
+			"error":   err.Error(),
+		})
+	}
+	if result.RowsAffected == 0 {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "Signal K source not found",
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"message": "Signal K source deleted successfully",
+	})
+}
+
+// Fleet publishes the fleet state as Signal K deltas
+// @Summary Get the fleet as Signal K deltas
+// @Description One delta per vessel with position, heading, speed, course and depth in SI units. Disabled unless SIGNALK_OUTPUT_ENABLED is set.
+// @Tags SignalK
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Router /api/signalk/deltas [get]
+func (c *SignalKController) Fleet(ctx http.Context) http.Response {
+	if !facades.Config().GetBool("tcp.signalk.output_enabled", false) {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "Signal K output is disabled",
+		})
+	}
+
+	instance, err := facades.App().Make("signalk_service")
+	if err != nil {
+		return ctx.Response().Json(http.StatusServiceUnavailable, http.Json{
+			"message": "Signal K service is not available",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, instance.(*services.SignalKService).FleetDeltas())
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+)
+
+type SoundingController struct {
+	// Dependent services
+}
+
+func NewSoundingController() *SoundingController {
+	return &SoundingController{
+		// Inject services
+	}
+}
+
+// Index returns the stored soundings of fathometer sessions
+// @Summary Get soundings
+// @Description Get the depth history of fathometer telnet sessions, newest first
+// @Tags Soundings
+// @Accept json
+// @Produce json
+// @Param session_id query int false "Telnet session ID"
+// @Param start_time query string false "Start time (RFC 3339)"
+// @Param end_time query string false "End time (RFC 3339)"
+// @Success 200 {object} http.Response
+// @Failure 400 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/soundings [get]
+func (c *SoundingController) Index(ctx http.Context) http.Response {
+	var soundings []models.Sounding
+
+	// Get query parameters for pagination
+	page, _ := strconv.Atoi(ctx.Request().Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Request().Query("limit", "100"))
+
+	// Initialize query builder
+	query := facades.Orm().Query()
+
+	// Apply filters if provided
+	if sessionID := ctx.Request().Query("session_id", ""); sessionID != "" {
+		query = query.Where("telnet_session_id", sessionID)
+	}
+
+	for _, bound := range []struct {
+		param     string
+		condition string
+	}{
+		{"start_time", "measured_at >= ?"},
+		{"end_time", "measured_at <= ?"},
+	} {
+		value := ctx.Request().Query(bound.param, "")
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "Invalid " + bound.param + ", expected RFC 3339",
+			})
+		}
+		query = query.Where(bound.condition, at)
+	}
+
+	// Get total count for pagination
+	var total int64
+	if err := query.Model(&models.Sounding{}).Count(&total); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to count soundings",
+			"error":   err.Error(),
+		})
+	}
+
+	// Execute the paginated query
+	offset := (page - 1) * limit
+	if err := query.Model(&models.Sounding{}).Order("measured_at DESC").Offset(offset).Limit(limit).Find(&soundings); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve soundings",
+			"error":   err.Error(),
+		})
+	}
+
+	// Return response with pagination info
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": soundings,
+		"meta": http.Json{
+			"current_page": page,
+			"per_page":     limit,
+			"total":        total,
+			"last_page":    (int(total) + limit - 1) / limit,
+		},
+	})
+}
@@ -53,15 +53,17 @@ type VesselData struct {
 }
 
 type RecordData struct {
-	Timestamp     time.Time `json:"timestamp"`
-	CallSign      string    `json:"call_sign"`
-	Latitude      string    `json:"latitude"`
-	Longitude     string    `json:"longitude"`
-	HeadingDegree float64   `json:"heading_degree"`
-	SpeedInKnots  float64   `json:"speed_in_knots"`
-	WaterDepth    float64   `json:"water_depth"`
-	TelnetStatus  string    `json:"telnet_status"`
-	SeriesID      int64     `json:"series_id"`
+	Timestamp     time.Time  `json:"timestamp"` // GNSS fix time, or arrival time for records without one
+	ReceivedAt    *time.Time `json:"received_at"`
+	CallSign      string     `json:"call_sign"`
+	Latitude      string     `json:"latitude"`
+	Longitude     string     `json:"longitude"`
+	HeadingDegree float64    `json:"heading_degree"`
+	SpeedInKnots  float64    `json:"speed_in_knots"`
+	WaterDepth    float64    `json:"water_depth"`
+	TelnetStatus  string     `json:"telnet_status"`
+	SeriesID      int64      `json:"series_id"`
+	VoyageID      *uint64    `json:"voyage_id"`
 }
 
 func NewVesselRecordController() *VesselRecordController {
@@ -89,7 +91,8 @@ func (c *VesselRecordController) StreamHistory(ctx contractshttp.Context) contra
 		var records []models.VesselRecord
 		query := facades.Orm().Query().Model(&models.VesselRecord{}).
 			Where("call_sign = ?", request.CallSign).
-			Where("created_at BETWEEN ? AND ?", request.StartTime, request.EndTime)
+			Where("((fix_time BETWEEN ? AND ?) OR (fix_time IS NULL AND created_at BETWEEN ? AND ?))",
+				request.StartTime, request.EndTime, request.StartTime, request.EndTime)
 
 		if request.Interval > 1 {
 			// Add interval filtering using a subquery or window function
@@ -115,8 +118,14 @@ func (c *VesselRecordController) StreamHistory(ctx contractshttp.Context) contra
 				lastID = record.ID
 			}
 
+			timestamp := record.CreatedAt
+			if record.FixTime != nil {
+				timestamp = *record.FixTime
+			}
+
 			recordData := RecordData{
-				Timestamp:     record.CreatedAt,
+				Timestamp:     timestamp,
+				ReceivedAt:    record.ReceivedAt,
 				CallSign:      record.CallSign,
 				Latitude:      record.Latitude,
 				Longitude:     record.Longitude,
@@ -125,6 +134,7 @@ func (c *VesselRecordController) StreamHistory(ctx contractshttp.Context) contra
 				WaterDepth:    record.WaterDepth,
 				TelnetStatus:  string(record.TelnetStatus),
 				SeriesID:      int64(record.SeriesID),
+				VoyageID:      record.VoyageID,
 			}
 
 			jsonData, err := json.Marshal(recordData)
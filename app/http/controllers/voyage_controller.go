@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+)
+
+type VoyageController struct {
+	// Dependent services
+}
+
+func NewVoyageController() *VoyageController {
+	return &VoyageController{
+		// Inject services
+	}
+}
+
+// Index returns the voyages of a vessel
+// @Summary Get voyages of a vessel
+// @Description Get the voyages detected from a vessel's records, newest first
+// @Tags Voyages
+// @Accept json
+// @Produce json
+// @Param call_sign path string true "Vessel call sign"
+// @Param status query string false "underway or completed"
+// @Param start_time query string false "Only voyages started at or after this time (RFC 3339)"
+// @Param end_time query string false "Only voyages started at or before this time (RFC 3339)"
+// @Success 200 {object} http.Response
+// @Failure 400 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/kapal/{call_sign}/voyages [get]
+func (c *VoyageController) Index(ctx http.Context) http.Response {
+	callSign := ctx.Request().Route("call_sign")
+	if response := c.findKapal(ctx, callSign); response != nil {
+		return response
+	}
+
+	var voyages []models.Voyage
+
+	// Get query parameters for pagination
+	page, _ := strconv.Atoi(ctx.Request().Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Request().Query("limit", "50"))
+
+	// Initialize query builder
+	query := facades.Orm().Query().Where("call_sign", callSign)
+
+	// Apply filters if provided
+	if status := ctx.Request().Query("status", ""); status != "" {
+		if status != models.VoyageUnderway && status != models.VoyageCompleted {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "Invalid status, expected underway or completed",
+			})
+		}
+		query = query.Where("status", status)
+	}
+
+	for _, bound := range []struct {
+		param     string
+		condition string
+	}{
+		{"start_time", "started_at >= ?"},
+		{"end_time", "started_at <= ?"},
+	} {
+		value := ctx.Request().Query(bound.param, "")
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ctx.Response().Json(http.StatusBadRequest, http.Json{
+				"message": "Invalid " + bound.param + ", expected RFC 3339",
+			})
+		}
+		query = query.Where(bound.condition, at)
+	}
+
+	// Get total count for pagination
+	var total int64
+	if err := query.Model(&models.Voyage{}).Count(&total); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to count voyages",
+			"error":   err.Error(),
+		})
+	}
+
+	// Execute the paginated query
+	offset := (page - 1) * limit
+	if err := query.Model(&models.Voyage{}).Order("started_at DESC").Offset(offset).Limit(limit).Find(&voyages); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve voyages",
+			"error":   err.Error(),
+		})
+	}
+
+	// Return response with pagination info
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": voyages,
+		"meta": http.Json{
+			"current_page": page,
+			"per_page":     limit,
+			"total":        total,
+			"last_page":    (int(total) + limit - 1) / limit,
+		},
+	})
+}
+
+// Show returns a single voyage of a vessel
+// @Summary Get a voyage
+// @Description Get a voyage of a vessel with the number of records linked to it
+// @Tags Voyages
+// @Accept json
+// @Produce json
+// @Param call_sign path string true "Vessel call sign"
+// @Param id path int true "Voyage ID"
+// @Success 200 {object} http.Response
+// @Failure 400 {object} http.Response
+// @Failure 404 {object} http.Response
+// @Failure 500 {object} http.Response
+// @Router /api/kapal/{call_sign}/voyages/{id} [get]
+func (c *VoyageController) Show(ctx http.Context) http.Response {
+	callSign := ctx.Request().Route("call_sign")
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 64)
+	if err != nil {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"message": "Invalid voyage ID",
+		})
+	}
+
+	var voyage models.Voyage
+	if err := facades.Orm().Query().Where("id", id).Where("call_sign", callSign).FirstOrFail(&voyage); err != nil {
+		if err.Error() == "record not found" {
+			return ctx.Response().Json(http.StatusNotFound, http.Json{
+				"message": "Voyage not found",
+			})
+		}
+
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve voyage",
+			"error":   err.Error(),
+		})
+	}
+
+	var records int64
+	if err := facades.Orm().Query().Model(&models.VesselRecord{}).Where("voyage_id", voyage.ID).Count(&records); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to count voyage records",
+			"error":   err.Error(),
+		})
+	}
+
+	return ctx.Response().Json(http.StatusOK, http.Json{
+		"data": voyage,
+		"meta": http.Json{
+			"records": records,
+		},
+	})
+}
+
+// findKapal returns a 404 response when the vessel does not exist
+func (c *VoyageController) findKapal(ctx http.Context, callSign string) http.Response {
+	var count int64
+	if err := facades.Orm().Query().Model(&models.Kapal{}).Where("call_sign", callSign).Count(&count); err != nil {
+		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
+			"message": "Failed to retrieve vessel",
+			"error":   err.Error(),
+		})
+	}
+	if count == 0 {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"message": "Vessel not found",
+		})
+	}
+	return nil
+}
@@ -2,6 +2,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"goravel/app/services"
 
 	"github.com/goravel/framework/contracts/http"
@@ -9,16 +11,16 @@ import (
 
 func Auth() http.Middleware {
     return func(ctx http.Context) {
-        token := ctx.Request().Header("Authorization")
+        token := strings.TrimPrefix(ctx.Request().Header("Authorization"), "Bearer ")
         if token == "" {
-            ctx.Response().Json(http.StatusUnauthorized, "Unauthorized")
+            ctx.Response().Json(http.StatusUnauthorized, "Unauthorized").Abort()
             return
         }
 
         jwtService := services.NewJwtService()
         _, err := jwtService.ValidateToken(token)
         if err != nil {
-            ctx.Response().Json(http.StatusUnauthorized, "Invalid token")
+            ctx.Response().Json(http.StatusUnauthorized, "Invalid token").Abort()
             return
         }
 
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// AisContact is a third-party vessel seen over AIS. Contacts are kept apart
+// from Kapal, which only holds our own fleet.
+type AisContact struct {
+	MMSI             uint32  `gorm:"primary_key;column:mmsi" json:"mmsi"`
+	Name             string  `gorm:"varchar(20)" json:"name"`
+	CallSign         string  `gorm:"varchar(7)" json:"call_sign"`
+	IMO              uint32  `gorm:"column:imo" json:"imo"`
+	ShipType         int     `json:"ship_type"`
+	AisClass         string  `gorm:"type:enum('A','B')" json:"ais_class"`
+	Destination      string  `gorm:"varchar(20)" json:"destination"`
+	Draught          float64 `json:"draught"`
+	DimensionToBow   int     `json:"dimension_to_bow"`
+	DimensionToStern int     `json:"dimension_to_stern"`
+	DimensionToPort  int     `json:"dimension_to_port"`
+	DimensionToStbd  int     `gorm:"column:dimension_to_starboard" json:"dimension_to_starboard"`
+
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	SpeedOverGround  float64 `json:"speed_over_ground"`
+	CourseOverGround float64 `json:"course_over_ground"`
+	TrueHeading      *int    `json:"true_heading"` // Nil when the transponder reports no heading
+	NavigationStatus int     `json:"navigation_status"`
+
+	OwnShip         bool      `json:"own_ship"`    // Reported via AIVDO by one of our vessels
+	ReceivedBy      string    `json:"received_by"` // Call sign or session that relayed the message
+	LastMessageType int       `json:"last_message_type"`
+	LastSeenAt      time.Time `gorm:"type:datetime" json:"last_seen_at"`
+
+	CreatedAt time.Time `gorm:"type:datetime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:datetime" json:"updated_at"`
+}
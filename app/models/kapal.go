@@ -20,6 +20,16 @@ type Kapal struct {
 	MinimumKnotPerLiterGasoline float64    `gorm:"not null;" json:"minimum_knot_per_liter_gasoline" binding:"required"`
 	MaximumKnotPerLiterGasoline float64    `gorm:"not null;" json:"maximum_knot_per_liter_gasoline" binding:"required"`
 	RecordStatus                bool       `gorm:"not null;" json:"record_status" binding:"required"`
+	DeviceSecretHash            string     `gorm:"varchar(64)" json:"-"`             // SHA-256 of the secret presented in the TCP handshake
+	SecretRotatedAt             *time.Time `gorm:"type:datetime" json:"secret_rotated_at"`
+	AckMode                     string     `gorm:"varchar(10)" json:"ack_mode"`         // none, echo or ack; empty uses the listener default
+	SourcePriority              string     `gorm:"varchar(255)" json:"source_priority"` // Sources most preferred first, e.g. "telnet:3,tcp"
+	MaxSpeedKnots               float64    `gorm:"" json:"max_speed_knots"`             // Fastest plausible speed between fixes; 0 uses the filter default
+	RecordMode                  string     `gorm:"varchar(10)" json:"record_mode"`      // interval or adaptive; empty uses the configured mode
+	DeadbandMeters              float64    `gorm:"" json:"deadband_meters"`             // Adaptive mode thresholds, 0 uses the configured default
+	DeadbandDegrees             float64    `gorm:"" json:"deadband_degrees"`
+	DeadbandKnots               float64    `gorm:"" json:"deadband_knots"`
+	MaxRecordInterval           int64      `gorm:"" json:"max_record_interval"` // Seconds
 	CreatedAt                   time.Time  `gorm:"type:datetime" json:"created_at"`
 	UpdatedAt                   time.Time  `gorm:"type:datetime" json:"updated_at"`
 	DeletedAt                   *time.Time `gorm:"index" json:"deleted_at"` // Add this field for soft delete
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RejectedFix is a position fix the plausibility filter kept out of a
+// vessel's track, stored for audit when tcp.plausibility.keep_rejected is set
+type RejectedFix struct {
+	ID                uint64     `gorm:"primary_key" json:"id"`
+	CallSign          string     `gorm:"not null;index" json:"call_sign"`
+	Source            string     `gorm:"varchar(32)" json:"source"` // Pipeline source, e.g. "tcp" or "telnet:3"
+	Latitude          float64    `json:"latitude"`                  // Decimal degrees
+	Longitude         float64    `json:"longitude"`
+	Reason            string     `gorm:"varchar(10)" json:"reason"` // invalid, speed or land
+	ImpliedSpeedKnots *float64   `json:"implied_speed_knots"`       // Speed from the last accepted fix, for speed rejections
+	FixTime           *time.Time `gorm:"type:datetime(3)" json:"fix_time"`
+	ReceivedAt        time.Time  `gorm:"type:datetime(3)" json:"received_at"`
+
+	CreatedAt time.Time `gorm:"type:datetime" json:"-"`
+	UpdatedAt time.Time `gorm:"type:datetime" json:"-"`
+}
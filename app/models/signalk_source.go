@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SignalKSource is a Signal K server streaming the data of one vessel
+type SignalKSource struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CallSign  string    `gorm:"not null;index" json:"call_sign" binding:"required"`
+	URL       string    `gorm:"column:url;varchar(255);not null" json:"url" binding:"required"` // ws://, wss:// or tcp:// stream address
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time `gorm:"type:datetime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:datetime" json:"updated_at"`
+	Kapal     *Kapal    `gorm:"foreignKey:CallSign;references:CallSign" json:"-"`
+}
+
+// TableName specifies the table name for the model
+func (s *SignalKSource) TableName() string {
+	return "signalk_sources"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Sounding is a depth measured by a fathometer telnet session, tagged with
+// the session's fixed position
+type Sounding struct {
+	ID              uint64   `gorm:"primary_key" json:"id"`
+	TelnetSessionID uint     `gorm:"not null;index" json:"telnet_session_id"`
+	Latitude        *float64 `json:"latitude"` // Decimal degrees, nil when the session has no position
+	Longitude       *float64 `json:"longitude"`
+	DepthMeters     float64  `json:"depth_meters"`             // Below the transducer, or below the surface for DBS
+	OffsetMeters    float64  `json:"offset_meters"`            // Transducer offset reported by DPT
+	Source          string   `gorm:"varchar(5)" json:"source"` // DBT, DPT, DBS or ASCII
+	RawData         string   `gorm:"type:TEXT" json:"raw_data"`
+
+	MeasuredAt time.Time `gorm:"type:datetime(3)" json:"measured_at"`
+	CreatedAt  time.Time `gorm:"type:datetime" json:"-"`
+	UpdatedAt  time.Time `gorm:"type:datetime" json:"-"`
+
+	TelnetSession *TelnetSession `gorm:"foreignKey:TelnetSessionID" json:"-"`
+}
@@ -30,15 +30,21 @@ const (
 type VesselRecord struct {
 	ID uint64 `gorm:"primary_key" json:"id"`
 	CallSign       string `gorm:"not null;index" json:"call_sign" binding:"required"`
-	SeriesID       uint64 `gorm:"not null" json:"series_id" binding:"required"`
+	SeriesID       uint64 `gorm:"not null" json:"series_id" binding:"required"` // Running number per call sign, kept for existing clients
+	VoyageID       *uint64 `gorm:"index" json:"voyage_id"` // Nil while the vessel is stationary
 
 	Latitude            string       `gorm:"varchar(255)" json:"latitude" binding:"required"`
 	Longitude           string       `gorm:"varchar(255)" json:"longitude" binding:"required"`
 	HeadingDegree       float64      `gorm:"varchar(255)" json:"heading_degree" binding:"required"`
+	RawHeadingDegree    float64      `gorm:"" json:"raw_heading_degree"` // Heading as received, before corrections
+	HeadingSource       string       `gorm:"varchar(3)" json:"heading_source"`
 	SpeedInKnots        float64      `gorm:"" json:"speed_in_knots" binding:"required"`
 	GpsQualityIndicator GpsQuality   `gorm:"type:enum('Fix not valid','GPS fix','Differential GPS fix','Not applicable','RTK Fixed','RTK Float','INS Dead reckoning');" json:"gps_quality_indicator"`
 	WaterDepth          float64      `gorm:"" json:"water_depth" binding:"required"`
 	TelnetStatus        TelnetStatus `gorm:"type:enum('Connected','Disconnected');default:'Connected'" json:"telnet_status" binding:"required"`
+	FixTime             *time.Time   `gorm:"type:datetime(3)" json:"fix_time"`    // UTC time reported by the GNSS receiver
+	ReceivedAt          *time.Time   `gorm:"type:datetime(3)" json:"received_at"` // Server time the position arrived
+	Source              string       `gorm:"varchar(32)" json:"source"`           // Pipeline source of the position, e.g. "tcp" or "telnet:3"
 
 	CreatedAt time.Time  `gorm:"type:datetime" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"type:datetime" json:"-"`
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// VesselTelemetryRecord is one environmental or transducer reading (water
+// temperature, wind, pressure, XDR values...) stored next to the vessel track
+type VesselTelemetryRecord struct {
+	ID       uint64  `gorm:"primary_key" json:"id"`
+	CallSign string  `gorm:"not null;index" json:"call_sign"`
+	Name     string  `gorm:"varchar(50)" json:"name"` // e.g. water_temperature, wind_speed_true, xdr_baro
+	Value    float64 `json:"value"`
+	Unit     string  `gorm:"varchar(10)" json:"unit"`
+	Source   string  `gorm:"varchar(3)" json:"source"` // Sentence type the reading came from
+
+	CreatedAt time.Time `gorm:"type:datetime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:datetime" json:"-"`
+
+	Kapal *Kapal `gorm:"foreignKey:CallSign;references:CallSign;constraint:OnDelete:NO ACTION" json:"-"`
+}
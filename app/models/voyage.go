@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Voyage states
+const (
+	VoyageUnderway  = "underway"
+	VoyageCompleted = "completed"
+)
+
+// Reasons a voyage ends
+const (
+	VoyageEndArrival = "arrival"  // The vessel stayed stationary for tcp.voyages.arrive_after
+	VoyageEndDataGap = "data_gap" // No record for tcp.voyages.gap_after
+)
+
+// Voyage is a passage of a vessel between two stationary periods, detected
+// from its vessel records. While underway the end position and statistics
+// follow the latest record.
+type Voyage struct {
+	ID             uint64     `gorm:"primary_key" json:"id"`
+	CallSign       string     `gorm:"not null;index" json:"call_sign"`
+	Status         string     `gorm:"varchar(10)" json:"status"`               // underway or completed
+	EndReason      string     `gorm:"varchar(10)" json:"end_reason,omitempty"` // arrival or data_gap
+	StartedAt      time.Time  `gorm:"type:datetime(3)" json:"started_at"`
+	StartLatitude  float64    `json:"start_latitude"` // Decimal degrees
+	StartLongitude float64    `json:"start_longitude"`
+	EndedAt        *time.Time `gorm:"type:datetime(3)" json:"ended_at"`
+	EndLatitude    float64    `json:"end_latitude"`
+	EndLongitude   float64    `json:"end_longitude"`
+	LastRecordAt   time.Time  `gorm:"type:datetime(3)" json:"last_record_at"`
+	DistanceMeters float64    `json:"distance_meters"`
+	MaxSpeedKnots  float64    `json:"max_speed_knots"`
+	AvgSpeedKnots  float64    `json:"avg_speed_knots"` // Distance over the time underway
+
+	CreatedAt time.Time `gorm:"type:datetime" json:"-"`
+	UpdatedAt time.Time `gorm:"type:datetime" json:"-"`
+
+	Kapal *Kapal `gorm:"foreignKey:CallSign;references:CallSign;constraint:OnDelete:NO ACTION" json:"-"`
+}
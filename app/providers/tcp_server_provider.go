@@ -2,6 +2,7 @@ package providers
 
 import (
 	"fmt"
+	"goravel/app/helpers/metrics"
 	"goravel/app/services"
 	"os"
 	"os/signal"
@@ -17,7 +18,13 @@ import (
 type TCPServerProvider struct {
     app              foundation.Application
     tcpVesselService *services.TCPVesselService
+    udpVesselService *services.UDPVesselService
+    ipKapalService   *services.IPKapalService
+    signalKService   *services.SignalKService
     tcpSensorService *services.TCPSensorService
+    aisService       *services.AISService
+    nmeaArchive      *services.NMEAArchive
+    recordWriter     *services.RecordWriter
     wsService        *services.WebSocketService
     shutdownChan     chan os.Signal
 }
@@ -26,20 +33,56 @@ type TCPServerProvider struct {
 func (provider *TCPServerProvider) Register(app foundation.Application) {
     fmt.Println("⚡ Registering TCP Server Provider")
     provider.app = app
-    provider.tcpVesselService = services.NewTCPVesselService()
-    provider.tcpSensorService = services.NewTCPSensorService()
-    provider.wsService = services.NewWebSocketService(provider.tcpVesselService, provider.tcpSensorService)
+    provider.aisService = services.NewAISService()
+    provider.nmeaArchive = services.NewNMEAArchive()
+    provider.recordWriter = services.NewRecordWriter()
+    provider.tcpVesselService = services.NewTCPVesselService(provider.aisService, provider.nmeaArchive, provider.recordWriter)
+    provider.udpVesselService = services.NewUDPVesselService(provider.tcpVesselService)
+    provider.ipKapalService = services.NewIPKapalService(provider.tcpVesselService)
+    provider.signalKService = services.NewSignalKService(provider.tcpVesselService)
+    provider.tcpSensorService = services.NewTCPSensorService(provider.recordWriter)
+    provider.wsService = services.NewWebSocketService(provider.tcpVesselService, provider.tcpSensorService, provider.aisService)
     provider.shutdownChan = make(chan os.Signal, 1)
 
+    // Report the gauges of the ingest pipeline on /metrics
+    provider.tcpVesselService.RegisterMetrics(metrics.Default)
+    provider.tcpSensorService.RegisterMetrics(metrics.Default)
+    provider.recordWriter.RegisterMetrics(metrics.Default)
+    provider.wsService.RegisterMetrics(metrics.Default)
+
     // Register services in the application container
     facades.App().Singleton("tcp_navigation_service", func(app foundation.Application) (any, error) {
         return provider.tcpVesselService, nil
     })
 
+    facades.App().Singleton("udp_navigation_service", func(app foundation.Application) (any, error) {
+        return provider.udpVesselService, nil
+    })
+
+    facades.App().Singleton("ip_kapal_service", func(app foundation.Application) (any, error) {
+        return provider.ipKapalService, nil
+    })
+
+    facades.App().Singleton("signalk_service", func(app foundation.Application) (any, error) {
+        return provider.signalKService, nil
+    })
+
     facades.App().Singleton("tcp_sensor_service", func(app foundation.Application) (any, error) {
         return provider.tcpSensorService, nil
     })
 
+    facades.App().Singleton("ais_service", func(app foundation.Application) (any, error) {
+        return provider.aisService, nil
+    })
+
+    facades.App().Singleton("nmea_archive", func(app foundation.Application) (any, error) {
+        return provider.nmeaArchive, nil
+    })
+
+    facades.App().Singleton("record_writer", func(app foundation.Application) (any, error) {
+        return provider.recordWriter, nil
+    })
+
     facades.App().Singleton("websocket_service", func(app foundation.Application) (any, error) {
         return provider.wsService, nil
     })
@@ -54,7 +97,13 @@ func (provider *TCPServerProvider) Boot(app foundation.Application) {
     fmt.Println("🚀 Booting TCP Server Provider")
     
     // Start services in separate goroutines for parallel initialization
+    go provider.aisService.Start()
+    go provider.nmeaArchive.Start()
+    go provider.recordWriter.Start()
     go provider.startVesselServer()
+    go provider.startUDPServer()
+    go provider.ipKapalService.Start()
+    go provider.signalKService.Start()
     go provider.startSensorServer()
 }
 
@@ -90,6 +139,13 @@ func (provider *TCPServerProvider) startVesselServer() {
     }
 }
 
+// startUDPServer starts the UDP navigation listener if a UDP port is configured
+func (provider *TCPServerProvider) startUDPServer() {
+    if err := provider.udpVesselService.Start(); err != nil {
+        facades.Log().Error(fmt.Sprintf("❌ UDP Navigation Server Error: %v", err))
+    }
+}
+
 // startSensorServer starts the sensor TCP server with retry logic
 func (provider *TCPServerProvider) startSensorServer() {
     maxRetries := 5
@@ -136,7 +192,7 @@ func (provider *TCPServerProvider) handleShutdown() {
         
         // Use wait group to manage concurrent shutdowns
         var wg sync.WaitGroup
-        wg.Add(2)
+        wg.Add(3)
         
         // Stop vessel service
         go func() {
@@ -149,6 +205,14 @@ func (provider *TCPServerProvider) handleShutdown() {
             }
         }()
         
+        // Stop UDP listener
+        go func() {
+            defer wg.Done()
+            if err := provider.udpVesselService.Stop(); err != nil {
+                facades.Log().Error(fmt.Sprintf("Error stopping UDP service: %v", err))
+            }
+        }()
+
         // Stop sensor service  
         go func() {
             defer wg.Done()
@@ -160,8 +224,17 @@ func (provider *TCPServerProvider) handleShutdown() {
             }
         }()
         
+        // Stop Signal K streams and outbound device connections
+        provider.signalKService.Stop()
+        provider.ipKapalService.Stop()
+
         // Wait for all services to stop
         wg.Wait()
+
+        // Flush AIS contacts, the raw archive and queued records once nothing can feed them anymore
+        provider.aisService.Stop()
+        provider.nmeaArchive.Stop()
+        provider.recordWriter.Stop()
         close(done)
     }()
     
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"fmt"
+	"goravel/app/helpers/metrics"
 	"goravel/app/services"
 	// "goravel/app/services"
 
@@ -12,13 +13,28 @@ import (
 type TelnetServiceProvider struct {
 	app           foundation.Application
 	telnetService *services.TelnetService
+	aisService    *services.AISService
+	ownsAIS       bool
+	nmeaArchive   *services.NMEAArchive
+	ownsArchive   bool
+	vessels       *services.TCPVesselService
+	ownsVessels   bool
 }
 
 func (provider *TelnetServiceProvider) Register(app foundation.Application) {
 	fmt.Println("Registering TelnetServiceProvider")
 
 	provider.app = app
-	provider.telnetService = services.NewTelnetService()
+	provider.aisService, provider.ownsAIS = provider.resolveAISService()
+	provider.nmeaArchive, provider.ownsArchive = provider.resolveNMEAArchive()
+	provider.vessels, provider.ownsVessels = provider.resolveVesselService()
+	provider.telnetService = services.NewTelnetService(provider.aisService, provider.nmeaArchive, provider.vessels)
+
+	provider.telnetService.RegisterMetrics(metrics.Default)
+	if provider.ownsVessels {
+		// TCPServerProvider registers the gauges of the shared pipeline
+		provider.vessels.RegisterMetrics(metrics.Default)
+	}
 
 	// Properly bind TelnetService
 	facades.App().Singleton("telnet_service", func(app foundation.Application) (any, error) {
@@ -28,8 +44,53 @@ func (provider *TelnetServiceProvider) Register(app foundation.Application) {
 
 func (provider *TelnetServiceProvider) Boot(app foundation.Application) {
 	fmt.Println("Booting TelnetServiceProvider")
+	if provider.ownsAIS {
+		provider.aisService.Start()
+	}
+	if provider.ownsArchive {
+		go provider.nmeaArchive.Start()
+	}
+	if provider.ownsVessels {
+		provider.vessels.CheckDisconnectedVessels()
+	}
+
 	err := provider.telnetService.Start()
 	if err != nil {
 		facades.Log().Error("Failed to start telnet service:", err)
 	}
 }
+
+// resolveAISService shares the AIS service registered by TCPServerProvider,
+// or creates a standalone one when that provider is not loaded
+func (provider *TelnetServiceProvider) resolveAISService() (*services.AISService, bool) {
+	if instance, err := facades.App().Make("ais_service"); err == nil {
+		if aisService, ok := instance.(*services.AISService); ok {
+			return aisService, false
+		}
+	}
+	return services.NewAISService(), true
+}
+
+// resolveNMEAArchive shares the raw archive registered by TCPServerProvider so
+// both write to the same files, or creates one when that provider is not loaded
+func (provider *TelnetServiceProvider) resolveNMEAArchive() (*services.NMEAArchive, bool) {
+	if instance, err := facades.App().Make("nmea_archive"); err == nil {
+		if archive, ok := instance.(*services.NMEAArchive); ok {
+			return archive, false
+		}
+	}
+	return services.NewNMEAArchive(), true
+}
+
+// resolveVesselService shares the vessel pipeline registered by
+// TCPServerProvider, so a vessel reachable over telnet and TCP fails over
+// between them instead of getting a record from each, or creates one
+// without a listener when that provider is not loaded
+func (provider *TelnetServiceProvider) resolveVesselService() (*services.TCPVesselService, bool) {
+	if instance, err := facades.App().Make("tcp_navigation_service"); err == nil {
+		if vessels, ok := instance.(*services.TCPVesselService); ok {
+			return vessels, false
+		}
+	}
+	return services.NewTCPVesselService(provider.aisService, provider.nmeaArchive, nil), true
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goravel/app/helpers/ais"
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// aisFlushInterval is how often changed contacts are written to the database
+const aisFlushInterval = 5 * time.Second
+
+// AISService decodes AIVDM/AIVDO traffic and tracks third-party AIS contacts
+type AISService struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	assembler *ais.Assembler
+
+	mutex    sync.RWMutex
+	contacts map[uint32]*models.AisContact // key is MMSI
+	dirty    map[uint32]bool               // contacts changed since the last flush
+
+	contactTimeout time.Duration
+}
+
+// NewAISService creates a new AIS service
+func NewAISService() *AISService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AISService{
+		ctx:            ctx,
+		cancel:         cancel,
+		assembler:      ais.NewAssembler(),
+		contacts:       make(map[uint32]*models.AisContact),
+		dirty:          make(map[uint32]bool),
+		contactTimeout: time.Duration(facades.Config().GetInt("tcp.ais.contact_timeout", 10)) * time.Minute,
+	}
+}
+
+// Start loads recently seen contacts and starts the periodic flush
+func (s *AISService) Start() {
+	var recent []models.AisContact
+	err := facades.Orm().Query().
+		Where("last_seen_at > ?", time.Now().Add(-s.contactTimeout)).
+		Find(&recent)
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to load recent AIS contacts: %v", err))
+	}
+
+	s.mutex.Lock()
+	for i := range recent {
+		s.contacts[recent[i].MMSI] = &recent[i]
+	}
+	s.mutex.Unlock()
+
+	go s.flushLoop()
+}
+
+// Stop writes any pending contact changes and stops the flush loop
+func (s *AISService) Stop() {
+	s.cancel()
+	s.flush()
+}
+
+// HandleSentence processes one AIVDM/AIVDO fragment. source identifies the
+// connection the fragment came in on and receivedBy the vessel or session
+// that relayed it.
+func (s *AISService) HandleSentence(source, receivedBy string, vdm nmea.VDM) {
+	payload, fillBits, complete := s.assembler.Add(source, vdm)
+	if !complete {
+		return
+	}
+
+	message, err := ais.Decode(payload, fillBits)
+	if err != nil {
+		if !errors.Is(err, ais.ErrUnsupportedMessage) {
+			facades.Log().Warning(fmt.Sprintf("Rejected AIS message from %s: %v", receivedBy, err))
+		}
+		return
+	}
+	if message.UserID() == 0 {
+		return
+	}
+
+	mmsi := message.UserID()
+	var loaded *models.AisContact
+	for {
+		s.mutex.Lock()
+		contact, exists := s.contacts[mmsi]
+		if !exists && loaded != nil {
+			contact, exists = loaded, true
+			s.contacts[mmsi] = contact
+		}
+		if exists {
+			applyAISMessage(contact, message)
+			contact.OwnShip = vdm.OwnShip
+			contact.ReceivedBy = receivedBy
+			contact.LastMessageType = message.Type()
+			contact.LastSeenAt = time.Now()
+			s.dirty[contact.MMSI] = true
+			s.mutex.Unlock()
+			return
+		}
+		s.mutex.Unlock()
+
+		// Outside the mutex, so a slow query does not hold up other sources
+		loaded = loadAISContact(mmsi)
+	}
+}
+
+// GetActiveContacts returns the contacts seen within the contact timeout, newest first
+func (s *AISService) GetActiveContacts() []models.AisContact {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	contacts := make([]models.AisContact, 0, len(s.contacts))
+	for _, contact := range s.contacts {
+		if time.Since(contact.LastSeenAt) <= s.contactTimeout {
+			contacts = append(contacts, *contact)
+		}
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].LastSeenAt.After(contacts[j].LastSeenAt)
+	})
+	return contacts
+}
+
+// loadAISContact reads the contact of an MMSI from the database, or returns
+// a new one when it has not been seen before
+func loadAISContact(mmsi uint32) *models.AisContact {
+	var contact models.AisContact
+	if err := facades.Orm().Query().Where("mmsi = ?", mmsi).First(&contact); err != nil || contact.MMSI == 0 {
+		now := time.Now()
+		contact = models.AisContact{
+			MMSI:             mmsi,
+			AisClass:         "A",
+			NavigationStatus: 15,
+			CreatedAt:        now,
+		}
+	}
+
+	return &contact
+}
+
+// flushLoop periodically persists changed contacts
+func (s *AISService) flushLoop() {
+	ticker := time.NewTicker(aisFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush saves changed contacts and forgets the ones that have gone quiet
+func (s *AISService) flush() {
+	s.mutex.Lock()
+	pending := make([]models.AisContact, 0, len(s.dirty))
+	for mmsi := range s.dirty {
+		pending = append(pending, *s.contacts[mmsi])
+	}
+	s.dirty = make(map[uint32]bool)
+
+	for mmsi, contact := range s.contacts {
+		if time.Since(contact.LastSeenAt) > s.contactTimeout {
+			delete(s.contacts, mmsi)
+		}
+	}
+	s.mutex.Unlock()
+
+	for i := range pending {
+		pending[i].UpdatedAt = time.Now()
+		if err := facades.Orm().Query().Save(&pending[i]); err != nil {
+			facades.Log().Error(fmt.Sprintf("Failed to save AIS contact %d: %v", pending[i].MMSI, err))
+		}
+	}
+}
+
+// applyAISMessage copies the fields carried by a decoded message into the contact
+func applyAISMessage(contact *models.AisContact, message ais.Message) {
+	switch m := message.(type) {
+	case ais.PositionReport:
+		applyAISPosition(contact, m)
+	case ais.ExtendedClassBReport:
+		applyAISPosition(contact, m.PositionReport)
+		contact.Name = m.Name
+		contact.ShipType = m.ShipType
+		applyAISDimensions(contact, m.Dimensions)
+	case ais.StaticVoyageData:
+		contact.AisClass = "A"
+		contact.Name = m.Name
+		contact.CallSign = m.CallSign
+		contact.IMO = m.IMO
+		contact.ShipType = m.ShipType
+		contact.Draught = m.Draught
+		contact.Destination = m.Destination
+		applyAISDimensions(contact, m.Dimensions)
+	case ais.StaticDataReport:
+		contact.AisClass = "B"
+		if m.PartNumber == 0 {
+			contact.Name = m.Name
+		} else {
+			contact.ShipType = m.ShipType
+			contact.CallSign = m.CallSign
+			applyAISDimensions(contact, m.Dimensions)
+		}
+	}
+}
+
+func applyAISPosition(contact *models.AisContact, report ais.PositionReport) {
+	contact.AisClass = "A"
+	if report.ClassB {
+		contact.AisClass = "B"
+	}
+	contact.NavigationStatus = report.NavigationStatus
+
+	if report.HasPosition {
+		contact.Latitude = report.Latitude
+		contact.Longitude = report.Longitude
+	}
+	if report.HasSpeed {
+		contact.SpeedOverGround = report.SpeedOverGround
+	}
+	if report.HasCourse {
+		contact.CourseOverGround = report.CourseOverGround
+	}
+	if report.HasHeading {
+		heading := report.TrueHeading
+		contact.TrueHeading = &heading
+	} else {
+		contact.TrueHeading = nil
+	}
+}
+
+func applyAISDimensions(contact *models.AisContact, dimensions ais.Dimensions) {
+	contact.DimensionToBow = dimensions.ToBow
+	contact.DimensionToStern = dimensions.ToStern
+	contact.DimensionToPort = dimensions.ToPort
+	contact.DimensionToStbd = dimensions.ToStarboard
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"time"
+
+	"goravel/app/helpers/geo"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// minCourseSpeedKnots is the speed below which the course over ground of a
+// GNSS receiver is noise and only the heading can steer an estimate
+const minCourseSpeedKnots = 1.0
+
+// deadReckoning projects the position of a vessel along its heading and
+// speed while position sentences are missing but heading and speed keep
+// arriving, so the vessel keeps moving on the map instead of freezing
+type deadReckoning struct {
+	enabled     bool
+	startAfter  time.Duration // Silence of the position sentences before projecting
+	maxDuration time.Duration // How long after the last fix estimates are made
+	record      bool          // Create records from estimates, with GPS quality INS Dead reckoning
+}
+
+// newDeadReckoning reads the tcp.dead_reckoning config section
+func newDeadReckoning() deadReckoning {
+	return deadReckoning{
+		enabled:     facades.Config().GetBool("tcp.dead_reckoning.enabled", false),
+		startAfter:  time.Duration(facades.Config().GetInt("tcp.dead_reckoning.start_after", 5)) * time.Second,
+		maxDuration: time.Duration(facades.Config().GetInt("tcp.dead_reckoning.max_duration", 300)) * time.Second,
+		record:      facades.Config().GetBool("tcp.dead_reckoning.record", false),
+	}
+}
+
+// project moves the estimate of a buffer whose fix is stale forward to now,
+// driven by heading and speed sentences. It reports whether the position was
+// updated. The caller must hold the buffer mutex.
+func (d deadReckoning) project(b *NMEABuffer, role string, now time.Time) bool {
+	if !d.enabled || (role != roleHeading && role != roleSpeed) || b.fixAt.IsZero() {
+		return false
+	}
+	if silence := now.Sub(b.fixAt); silence < d.startAfter || silence > d.maxDuration {
+		return false
+	}
+
+	// Without a current speed the distance run is unknown
+	if now.Sub(b.lastVTGFix) > d.startAfter {
+		return false
+	}
+	course, ok := d.course(b, now)
+	if !ok {
+		return false
+	}
+
+	// Each estimate continues from the previous one, so turns are followed
+	if !b.estimateAt.After(b.fixAt) {
+		b.estimateLatitude, b.estimateLongitude, b.estimateAt = b.fixLatitude, b.fixLongitude, b.fixAt
+	}
+	distance := b.SpeedInKnots * geo.MetresPerNauticalMile * now.Sub(b.estimateAt).Hours()
+	b.estimateLatitude, b.estimateLongitude = geo.Destination(b.estimateLatitude, b.estimateLongitude, course, distance)
+	b.estimateAt = now
+
+	b.Latitude = formatCoordinate(b.estimateLatitude, "N", "S")
+	b.Longitude = formatCoordinate(b.estimateLongitude, "E", "W")
+	b.Estimated = true
+	b.GpsQualityIndicator = models.InsDeadReckoning
+	b.PositionFixTime = time.Time{}
+	b.LastPositionTime = now
+	return true
+}
+
+// course prefers the heading, which a gyro keeps delivering when the GNSS
+// receiver is gone, and falls back to the course over ground while moving
+func (d deadReckoning) course(b *NMEABuffer, now time.Time) (float64, bool) {
+	if now.Sub(b.headingAt) <= d.startAfter {
+		return b.HeadingDegree, true
+	}
+	if b.SpeedInKnots >= minCourseSpeedKnots {
+		return b.CourseOverGround, true
+	}
+	return 0, false
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"goravel/app/helpers/metrics"
+	"goravel/app/helpers/nmea"
+)
+
+// Counters of the ingest pipeline. Call signs and sensor IDs are only used as
+// labels once they matched a database row, so a sender cannot grow the series
+// without bound.
+var (
+	metricSentences = metrics.Default.NewCounterVec("nmea_sentences_received_total",
+		"NMEA sentences received by vessel and sentence type, other for types the parser does not use", "call_sign", "type")
+	metricParseFailures = metrics.Default.NewCounterVec("nmea_parse_failures_total",
+		"Sentences rejected as malformed, checksum failures excluded", "call_sign")
+	metricChecksumFailures = metrics.Default.NewCounterVec("nmea_checksum_failures_total",
+		"Sentences with a missing, malformed or mismatching checksum", "call_sign")
+	metricUnknownCallSigns = metrics.Default.NewCounterVec("nmea_unknown_call_sign_total",
+		"Database lookups of call signs that matched no vessel, at most one per call sign per cache period")
+	metricRecordsWritten = metrics.Default.NewCounterVec("records_written_total",
+		"Rows inserted into the record tables", "table")
+	metricRecordsFailed = metrics.Default.NewCounterVec("records_write_failures_total",
+		"Rows of inserts the database refused", "table")
+	metricSensorMessages = metrics.Default.NewCounterVec("sensor_messages_received_total",
+		"Messages received from known sensors", "sensor_id")
+)
+
+// lastWrites is the duration of the latest insert into each record table
+var lastWrites = struct {
+	sync.Mutex
+	durations map[string]time.Duration
+}{durations: make(map[string]time.Duration)}
+
+// observeWrite counts an insert of records into a table
+func observeWrite(table string, records int, duration time.Duration, ok bool) {
+	if ok {
+		metricRecordsWritten.Add(float64(records), table)
+	} else {
+		metricRecordsFailed.Add(float64(records), table)
+	}
+
+	lastWrites.Lock()
+	lastWrites.durations[table] = duration
+	lastWrites.Unlock()
+}
+
+// parseSentence parses a line of a vessel and counts it as received, as a
+// parse failure or as a checksum failure. Every source that decodes vessel
+// sentences goes through here.
+func parseSentence(parser *nmea.Parser, callSign string, raw string) (nmea.Sentence, error) {
+	sentence, err := parser.Parse(raw)
+	switch {
+	case err == nil:
+		metricSentences.Inc(callSign, nmea.SentenceType(raw))
+	case errors.Is(err, nmea.ErrEmpty):
+	case errors.Is(err, nmea.ErrUnsupportedType):
+		metricSentences.Inc(callSign, "other")
+	case isChecksumError(err):
+		metricChecksumFailures.Inc(callSign)
+	default:
+		metricParseFailures.Inc(callSign)
+	}
+	return sentence, err
+}
+
+// isChecksumError reports whether a sentence failed on its checksum
+func isChecksumError(err error) bool {
+	return errors.Is(err, nmea.ErrChecksumMismatch) || errors.Is(err, nmea.ErrInvalidChecksum) || errors.Is(err, nmea.ErrMissingChecksum)
+}
+
+// countUnknownCallSign counts a vessel lookup that missed the database
+func countUnknownCallSign(err error) {
+	if err != nil && err.Error() == "record not found" {
+		metricUnknownCallSigns.Inc()
+	}
+}
+
+// RegisterMetrics adds the gauges of the vessel pipeline
+func (s *TCPVesselService) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("vessel_tcp_connections", "Open connections to the navigation listener", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(s.connections.Load()))
+		})
+	r.NewGaugeFunc("nmea_buffers", "Vessels with a live NMEA buffer", nil,
+		func(emit func(float64, ...string)) {
+			s.bufferMutex.Lock()
+			defer s.bufferMutex.Unlock()
+			emit(float64(len(s.nmeaBuffers)))
+		})
+}
+
+// RegisterMetrics adds the gauges of the sensor listener
+func (s *TCPSensorService) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("sensor_tcp_connections", "Open connections to the sensor listener", nil,
+		func(emit func(float64, ...string)) {
+			s.connMutex.Lock()
+			defer s.connMutex.Unlock()
+			emit(float64(s.activeConnections))
+		})
+}
+
+// RegisterMetrics adds the gauges of the telnet sessions
+func (ts *TelnetService) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("telnet_session_state", "1 for the current state of each telnet session", []string{"session_id", "call_sign", "state"},
+		func(emit func(float64, ...string)) {
+			for _, session := range ts.GetSessionStates() {
+				callSign := ""
+				if session.CallSign != nil {
+					callSign = *session.CallSign
+				}
+				emit(1, strconv.FormatUint(uint64(session.ID), 10), callSign, session.State)
+			}
+		})
+}
+
+// RegisterMetrics adds the gauge of the connected WebSocket clients
+func (ws *WebSocketService) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("websocket_clients", "Connected WebSocket clients", nil,
+		func(emit func(float64, ...string)) {
+			ws.mutex.RLock()
+			defer ws.mutex.RUnlock()
+			emit(float64(len(ws.clients)))
+		})
+}
+
+// RegisterMetrics adds the gauges of the record tables and their queues
+func (w *RecordWriter) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("db_write_duration_seconds", "Duration of the latest insert into each record table", []string{"table"},
+		func(emit func(float64, ...string)) {
+			lastWrites.Lock()
+			defer lastWrites.Unlock()
+			for table, duration := range lastWrites.durations {
+				emit(duration.Seconds(), table)
+			}
+		})
+	r.NewGaugeFunc("write_queue_depth", "Records waiting in the write-behind queue of each table", []string{"table"},
+		func(emit func(float64, ...string)) {
+			for _, stats := range w.Stats() {
+				emit(float64(stats.Depth), stats.Table)
+			}
+		})
+	r.NewGaugeFunc("write_queue_latency_seconds", "How long the oldest record of the latest batch waited in the queue", []string{"table"},
+		func(emit func(float64, ...string)) {
+			for _, stats := range w.Stats() {
+				emit(float64(stats.LastLatencyMs)/1000, stats.Table)
+			}
+		})
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+)
+
+func TestTelnetSentencesAreCounted(t *testing.T) {
+	callSign, typeIP := "TELNET-METRICS", "all"
+	session := &models.TelnetSession{ID: 7, Name: "bridge", CallSign: &callSign, TypeIP: &typeIP}
+	ts := &TelnetService{parser: &nmea.Parser{}}
+
+	// A sentence type the pipeline does not use stops after counting, before
+	// any vessel lookup
+	ts.processNMEAData(session, "$GPXTE,A,A,0.67,L,N")
+	if got := metricSentences.Value(callSign, "other"); got != 1 {
+		t.Errorf("received other is %v, want 1", got)
+	}
+}
+
+func TestParseSentenceCounts(t *testing.T) {
+	callSign := "PARSE-METRICS"
+	parser := &nmea.Parser{}
+
+	if _, err := parseSentence(parser, callSign, "$GPHDT,274.07,T*03"); err != nil {
+		t.Fatal(err)
+	}
+	parseSentence(parser, callSign, "$GPHDT,274.07,T*04")
+	parseSentence(parser, callSign, "$GPHDT,north,T")
+	parseSentence(parser, callSign, "")
+
+	for name, c := range map[string]struct {
+		got, want float64
+	}{
+		"received HDT":      {metricSentences.Value(callSign, nmea.TypeHDT), 1},
+		"checksum failures": {metricChecksumFailures.Value(callSign), 1},
+		"parse failures":    {metricParseFailures.Value(callSign), 1},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s is %v, want %v", name, c.got, c.want)
+		}
+	}
+}
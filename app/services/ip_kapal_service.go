@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+const (
+	ipPollRefreshInterval = 30 * time.Second
+	ipPollMinBackoff      = 5 * time.Second
+	ipPollMaxBackoff      = 1 * time.Minute
+)
+
+// Connection states of an IPKapal entry
+const (
+	IPStatusConnecting   = "connecting"
+	IPStatusConnected    = "connected"
+	IPStatusDisconnected = "disconnected"
+)
+
+// IPConnectionState reports the outbound connection of one IPKapal entry
+type IPConnectionState struct {
+	ID          uint          `json:"id"`
+	CallSign    string        `json:"call_sign"`
+	TypeIP      models.TypeIP `json:"type_ip"`
+	Address     string        `json:"address"`
+	Status      string        `json:"status"`
+	ConnectedAt *time.Time    `json:"connected_at"`
+	LastDataAt  *time.Time    `json:"last_data_at"`
+	LastError   string        `json:"last_error,omitempty"`
+	Lines       uint64        `json:"lines"`
+	Ignored     uint64        `json:"ignored"` // Sentences outside the entry's type_ip
+	Reconnects  uint64        `json:"reconnects"`
+}
+
+// IPKapalService dials the device behind every IPKapal entry and feeds the
+// entry's sentence family into the vessel pipeline. A vessel with separate
+// GPS, gyro and sounder entries ends up with one merged NMEABuffer, shared
+// with anything the vessel sends to the TCP listener itself.
+type IPKapalService struct {
+	vessels     *TCPVesselService
+	enabled     bool
+	idleTimeout time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mutex   sync.Mutex
+	pollers map[uint]*ipPoller // key is IPKapal ID
+}
+
+// ipPoller is the connection loop of one entry; state is guarded by the
+// service mutex
+type ipPoller struct {
+	entry  models.IPKapal
+	cancel context.CancelFunc
+	state  IPConnectionState
+}
+
+// NewIPKapalService creates the poller from the tcp.polling config section
+func NewIPKapalService(vessels *TCPVesselService) *IPKapalService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &IPKapalService{
+		vessels:     vessels,
+		enabled:     facades.Config().GetBool("tcp.polling.enabled", false),
+		idleTimeout: time.Duration(facades.Config().GetInt("tcp.polling.idle_timeout", 60)) * time.Second,
+		ctx:         ctx,
+		cancel:      cancel,
+		pollers:     make(map[uint]*ipPoller),
+	}
+}
+
+// Start follows the IPKapal table until Stop is called
+func (s *IPKapalService) Start() {
+	if !s.enabled {
+		return
+	}
+	facades.Log().Info("📡 Polling vessel devices from IPKapal entries")
+
+	ticker := time.NewTicker(ipPollRefreshInterval)
+	defer ticker.Stop()
+
+	s.updateEntries()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.updateEntries()
+		}
+	}
+}
+
+// updateEntries starts pollers for new entries, restarts those whose
+// address or type changed and stops those that were deleted
+func (s *IPKapalService) updateEntries() {
+	var entries []models.IPKapal
+	if err := facades.Orm().Query().Find(&entries); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to fetch IPKapal entries: %v", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := make(map[uint]bool)
+	for _, entry := range entries {
+		current[entry.ID] = true
+
+		if poller, exists := s.pollers[entry.ID]; exists {
+			if poller.entry.IP == entry.IP && poller.entry.Port == entry.Port &&
+				poller.entry.TypeIP == entry.TypeIP && poller.entry.CallSign == entry.CallSign {
+				continue
+			}
+			poller.cancel()
+			delete(s.pollers, entry.ID)
+		}
+		s.startPoller(entry)
+	}
+
+	for id, poller := range s.pollers {
+		if !current[id] {
+			poller.cancel()
+			delete(s.pollers, id)
+		}
+	}
+}
+
+// startPoller runs the connection loop of an entry. The caller holds the mutex.
+func (s *IPKapalService) startPoller(entry models.IPKapal) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	poller := &ipPoller{
+		entry:  entry,
+		cancel: cancel,
+		state: IPConnectionState{
+			ID:       entry.ID,
+			CallSign: entry.CallSign,
+			TypeIP:   entry.TypeIP,
+			Address:  net.JoinHostPort(entry.IP, strconv.Itoa(int(entry.Port))),
+			Status:   IPStatusDisconnected,
+		},
+	}
+	s.pollers[entry.ID] = poller
+	go s.runPoller(ctx, poller)
+}
+
+// runPoller keeps the entry connected, backing off between failed attempts
+func (s *IPKapalService) runPoller(ctx context.Context, poller *ipPoller) {
+	backoff := ipPollMinBackoff
+	for {
+		connected, err := s.poll(ctx, poller)
+		if ctx.Err() != nil {
+			s.updateState(poller, func(state *IPConnectionState) { state.Status = IPStatusDisconnected })
+			return
+		}
+		if connected {
+			backoff = ipPollMinBackoff
+		}
+
+		s.updateState(poller, func(state *IPConnectionState) {
+			state.Status = IPStatusDisconnected
+			state.Reconnects++
+			if err != nil {
+				state.LastError = err.Error()
+			}
+		})
+		if err != nil {
+			facades.Log().Warning(fmt.Sprintf("IPKapal %d (%s %s): %v, retrying in %v", poller.entry.ID, poller.entry.CallSign, poller.state.Address, err, backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > ipPollMaxBackoff {
+			backoff = ipPollMaxBackoff
+		}
+	}
+}
+
+// poll reads one connection until it fails, goes idle or ctx ends, and
+// reports whether the connection was established
+func (s *IPKapalService) poll(ctx context.Context, poller *ipPoller) (bool, error) {
+	s.updateState(poller, func(state *IPConnectionState) { state.Status = IPStatusConnecting })
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", poller.state.Address)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// Unblock the read when the poller is stopped
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	now := time.Now()
+	s.updateState(poller, func(state *IPConnectionState) {
+		state.Status = IPStatusConnected
+		state.ConnectedAt = &now
+		state.LastError = ""
+	})
+
+	vc := newVesselConnection(conn, s.vessels.maxLineLength)
+	for {
+		if s.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+
+		line, err := vc.readLine()
+		if errors.Is(err, errLineTooLong) {
+			continue
+		}
+		if line != "" {
+			s.handleLine(poller, line)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return true, nil
+			}
+			return true, err
+		}
+	}
+}
+
+// handleLine feeds a sentence of the entry's family into the vessel pipeline
+func (s *IPKapalService) handleLine(poller *ipPoller, line string) {
+	if len(line) < 6 || (line[0] != '$' && line[0] != '!') ||
+		!acceptsSentenceType(string(poller.entry.TypeIP), nmea.SentenceType(line)) {
+		s.updateState(poller, func(state *IPConnectionState) { state.Ignored++ })
+		return
+	}
+
+	now := time.Now()
+	s.updateState(poller, func(state *IPConnectionState) {
+		state.Lines++
+		state.LastDataAt = &now
+	})
+
+	kapal, err := s.vessels.getKapal(poller.entry.CallSign)
+	if err != nil {
+		return
+	}
+
+	s.vessels.trackVessel(kapal)
+	s.vessels.archive.Record(kapal.CallSign, poller.state.Address, line, now)
+	s.vessels.processVesselData(*kapal, sourceName(SourceIP, poller.entry.ID), line, now)
+}
+
+func (s *IPKapalService) updateState(poller *ipPoller, update func(*IPConnectionState)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	update(&poller.state)
+}
+
+// GetConnectionStates returns the connection state of every entry, by ID
+func (s *IPKapalService) GetConnectionStates() []IPConnectionState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	states := make([]IPConnectionState, 0, len(s.pollers))
+	for _, poller := range s.pollers {
+		states = append(states, poller.state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+// Enabled reports whether IPKapal entries are being polled
+func (s *IPKapalService) Enabled() bool {
+	return s.enabled
+}
+
+// Stop closes every connection
+func (s *IPKapalService) Stop() {
+	s.cancel()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, poller := range s.pollers {
+		poller.cancel()
+		delete(s.pollers, id)
+	}
+}
+
+// acceptsSentenceType reports whether a sentence type belongs to the family
+// a type_ip selects; "all" accepts everything
+func acceptsSentenceType(typeIP string, sentenceType string) bool {
+	switch typeIP {
+	case string(models.ALL):
+		return true
+	case string(models.GGA):
+		return sentenceType == nmea.TypeGGA
+	case string(models.HDT):
+		return sentenceType == nmea.TypeHDT || sentenceType == nmea.TypeHDG || sentenceType == nmea.TypeHDM
+	case string(models.VTG):
+		return sentenceType == nmea.TypeVTG
+	case string(models.DEPTH):
+		return sentenceType == nmea.TypeDBT || sentenceType == nmea.TypeDPT
+	default:
+		return false
+	}
+}
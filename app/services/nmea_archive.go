@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+const (
+	archiveFlushInterval = 5 * time.Second
+	archiveCleanInterval = 1 * time.Hour
+	archiveDayLayout     = "2006-01-02"
+	archiveExtension     = ".nmea.gz"
+)
+
+// NMEAArchive keeps every raw line received from a vessel in gzip files, one
+// per vessel per UTC day. Lines are written as
+// "<received at RFC3339Nano>\t<source address>\t<sentence>". Files are opened
+// for appending, so a restart adds a new gzip member to the day's file, which
+// gzip readers handle transparently.
+type NMEAArchive struct {
+	enabled       bool
+	directory     string
+	retentionDays int
+
+	mutex    sync.Mutex
+	files    map[string]*archiveFile // key is CallSign
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// archiveFile is the open file of one vessel for the current day
+type archiveFile struct {
+	day    string
+	file   *os.File
+	writer *gzip.Writer
+	dirty  bool
+}
+
+// NewNMEAArchive creates the archive from the tcp.archive config section
+func NewNMEAArchive() *NMEAArchive {
+	return &NMEAArchive{
+		enabled:       facades.Config().GetBool("tcp.archive.enabled", false),
+		directory:     facades.Config().GetString("tcp.archive.path", "storage/app/nmea"),
+		retentionDays: facades.Config().GetInt("tcp.archive.retention_days", 90),
+		files:         make(map[string]*archiveFile),
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// Enabled reports whether raw lines are being archived
+func (a *NMEAArchive) Enabled() bool {
+	return a != nil && a.enabled
+}
+
+// Start flushes open files periodically and removes files past the retention
+// period. It returns when Stop is called.
+func (a *NMEAArchive) Start() {
+	if !a.Enabled() {
+		return
+	}
+	facades.Log().Info("🗄️ Archiving raw NMEA to " + a.directory)
+
+	a.removeExpired()
+	flushTicker := time.NewTicker(archiveFlushInterval)
+	cleanTicker := time.NewTicker(archiveCleanInterval)
+	defer flushTicker.Stop()
+	defer cleanTicker.Stop()
+
+	for {
+		select {
+		case <-a.stopChan:
+			return
+		case <-flushTicker.C:
+			a.flushAll()
+		case <-cleanTicker.C:
+			a.removeExpired()
+		}
+	}
+}
+
+// Record appends a raw line to the vessel's file for the day it was received
+func (a *NMEAArchive) Record(callSign string, source string, line string, receivedAt time.Time) {
+	if !a.Enabled() || callSign == "" {
+		return
+	}
+	receivedAt = receivedAt.UTC()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	file, err := a.fileFor(callSign, receivedAt.Format(archiveDayLayout))
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to open NMEA archive for %s: %v", callSign, err))
+		return
+	}
+
+	if _, err := fmt.Fprintf(file.writer, "%s\t%s\t%s\n", receivedAt.Format(time.RFC3339Nano), source, line); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to archive NMEA for %s: %v", callSign, err))
+		return
+	}
+	file.dirty = true
+}
+
+// fileFor returns the open file for the vessel and day, rotating away from
+// the previous day's file. The caller holds the mutex.
+func (a *NMEAArchive) fileFor(callSign string, day string) (*archiveFile, error) {
+	if file, exists := a.files[callSign]; exists {
+		if file.day == day {
+			return file, nil
+		}
+		file.close()
+		delete(a.files, callSign)
+	}
+
+	directory := filepath.Join(a.directory, archiveName(callSign))
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	handle, err := os.OpenFile(filepath.Join(directory, day+archiveExtension), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &archiveFile{day: day, file: handle, writer: gzip.NewWriter(handle)}
+	a.files[callSign] = file
+	return file, nil
+}
+
+// flushAll makes buffered lines readable and closes files of vessels that
+// have not sent anything since the day changed
+func (a *NMEAArchive) flushAll() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	today := time.Now().UTC().Format(archiveDayLayout)
+	for callSign, file := range a.files {
+		if file.day != today {
+			file.close()
+			delete(a.files, callSign)
+			continue
+		}
+		file.flush()
+	}
+}
+
+// removeExpired deletes day files older than the retention period
+func (a *NMEAArchive) removeExpired() {
+	if a.retentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -a.retentionDays).Format(archiveDayLayout)
+
+	paths, err := filepath.Glob(filepath.Join(a.directory, "*", "*"+archiveExtension))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		// Day names sort chronologically
+		if day := strings.TrimSuffix(filepath.Base(path), archiveExtension); day < cutoff {
+			if err := os.Remove(path); err != nil {
+				facades.Log().Warning(fmt.Sprintf("Failed to remove expired NMEA archive %s: %v", path, err))
+			}
+		}
+	}
+}
+
+// Export writes the archived lines of a vessel received within [from, to] to
+// w, oldest first
+func (a *NMEAArchive) Export(callSign string, from time.Time, to time.Time, w io.Writer) error {
+	if !a.Enabled() {
+		return errors.New("NMEA archive is disabled")
+	}
+	from, to = from.UTC(), to.UTC()
+
+	// Make the lines of the current file readable first
+	a.mutex.Lock()
+	if file, exists := a.files[callSign]; exists {
+		file.flush()
+	}
+	a.mutex.Unlock()
+
+	for _, path := range a.dayFiles(callSign, from, to) {
+		if err := exportFile(path, from, to, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dayFiles lists the existing files of a vessel whose day overlaps [from, to]
+func (a *NMEAArchive) dayFiles(callSign string, from time.Time, to time.Time) []string {
+	paths, err := filepath.Glob(filepath.Join(a.directory, archiveName(callSign), "*"+archiveExtension))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(paths)
+
+	first, last := from.Format(archiveDayLayout), to.Format(archiveDayLayout)
+	var matching []string
+	for _, path := range paths {
+		day := strings.TrimSuffix(filepath.Base(path), archiveExtension)
+		if day >= first && day <= last {
+			matching = append(matching, path)
+		}
+	}
+	return matching
+}
+
+// exportFile copies the lines of one day file that fall within [from, to]
+func exportFile(path string, from time.Time, to time.Time, w io.Writer) error {
+	handle, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	reader, err := gzip.NewReader(handle)
+	if errors.Is(err, io.EOF) {
+		return nil // Created but nothing flushed yet
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		stamp, _, _ := strings.Cut(line, "\t")
+		receivedAt, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil || receivedAt.Before(from) || receivedAt.After(to) {
+			continue
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	// The file being written ends in a flushed but unterminated gzip member
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Stop flushes and closes every open file
+func (a *NMEAArchive) Stop() {
+	if !a.Enabled() {
+		return
+	}
+	a.stopOnce.Do(func() { close(a.stopChan) })
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for callSign, file := range a.files {
+		file.close()
+		delete(a.files, callSign)
+	}
+}
+
+func (f *archiveFile) flush() {
+	if !f.dirty {
+		return
+	}
+	if err := f.writer.Flush(); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to flush NMEA archive %s: %v", f.file.Name(), err))
+	}
+	f.dirty = false
+}
+
+func (f *archiveFile) close() {
+	if err := f.writer.Close(); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to close NMEA archive %s: %v", f.file.Name(), err))
+	}
+	f.file.Close()
+}
+
+// archiveName turns a call sign into a safe directory name
+func archiveName(callSign string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, callSign)
+}
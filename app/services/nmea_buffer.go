@@ -0,0 +1,309 @@
+package services
+
+import (
+	"fmt"
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Position precedence when a vessel sends both GGA and RMC
+const (
+	PrecedenceGGA    = "gga"    // RMC positions are only used while GGA is stale
+	PrecedenceRMC    = "rmc"    // GGA positions are only used while RMC is stale
+	PrecedenceLatest = "latest" // Whichever sentence arrived last wins
+)
+
+// positionSourceTimeout is how long a preferred position source may be
+// silent before the other one is allowed to take over
+const positionSourceTimeout = 10 * time.Second
+
+type NMEABuffer struct {
+	Latitude            string
+	Longitude           string
+	HeadingDegree       float64 // True heading with the vessel's calibration applied
+	RawHeadingDegree    float64 // Heading as received, before any correction
+	HeadingSource       string  // Sentence type the heading came from (HDT, HDG or HDM)
+	PositionSource      string  // Pipeline source of the current position, e.g. "tcp" or "telnet:3"
+	Estimated           bool    // Position is projected from the last fix by dead reckoning
+	HeadingCalibration  float64 // Calibration offset applied to the last heading
+	MagneticVariation   float64 // Degrees, east positive, from HDG or RMC
+	SpeedInKnots        float64
+	CourseOverGround    float64
+	GpsQualityIndicator models.GpsQuality
+	FixValid            bool
+	FixTime             time.Time     // Latest UTC date and time reported by the receiver (ZDA, RMC or GGA)
+	PositionFixTime     time.Time     // Receiver UTC time of the current position, zero if the sentence had none
+	ClockSkew           time.Duration // Arrival time minus receiver time: link latency plus clock offset
+	WaterDepth          float64
+	Environment         map[string]EnvironmentReading // MTW, MWV, MDA and XDR readings by name
+	LastGGATime         time.Time
+	LastRMCTime         time.Time
+	LastPositionTime    time.Time // Track when the position was last updated by any sentence
+	LastRecordTime      time.Time
+	LastVTGTime         time.Time // Track when we last got VTG data
+	LastHDTTime         time.Time // Track when we last got HDT data
+	mutex               sync.Mutex
+
+	// Times of the last usable fix per source, used for precedence
+	lastGGAFix time.Time
+	lastRMCFix time.Time
+	lastVTGFix time.Time
+	lastHDTFix time.Time
+	headingAt  time.Time // Last heading from any of HDT, HDG or HDM
+
+	// Last received fix in decimal degrees and the dead reckoning estimate
+	// projected from it
+	fixLatitude       float64
+	fixLongitude      float64
+	fixAt             time.Time
+	estimateLatitude  float64
+	estimateLongitude float64
+	estimateAt        time.Time
+
+	// State of the last record, compared against the deadband
+	hasRecorded       bool
+	recordedLatitude  float64
+	recordedLongitude float64
+	recordedCourse    float64
+	recordedSpeed     float64
+}
+
+// Add this method to NMEABuffer
+func (b *NMEABuffer) GetMutex() *sync.Mutex {
+	return &b.mutex
+}
+
+// applySentence copies the values of a decoded sentence into the buffer and
+// reports whether the position was updated. calibration is the vessel's
+// heading offset in degrees and now the time the sentence arrived. The caller
+// must hold the buffer mutex.
+func (b *NMEABuffer) applySentence(sentence nmea.Sentence, calibration float64, precedence string, now time.Time) bool {
+	switch s := sentence.(type) {
+	case nmea.GGA:
+		b.LastGGATime = now
+		var fixTime time.Time
+		if s.HasTime {
+			// GGA only carries the time of day, take the date from the receiver's clock
+			fixTime = nmea.ResolveTimeOfDay(s.Time, now.Add(-b.ClockSkew))
+			b.updateFixTime(fixTime, now)
+		}
+		if !s.HasPosition || !b.acceptsPosition(nmea.TypeGGA, precedence, now) {
+			return false
+		}
+		b.lastGGAFix = now
+		b.PositionFixTime = fixTime
+		b.setFix(s.Latitude, s.Longitude, now)
+		b.GpsQualityIndicator = getGpsQualityFromIndicator(s.FixQuality)
+		b.FixValid = s.FixQuality > 0
+		b.LastPositionTime = now
+		return true
+	case nmea.RMC:
+		b.LastRMCTime = now
+		var fixTime time.Time
+		if s.HasDateTime {
+			fixTime = s.DateTime
+			b.updateFixTime(fixTime, now)
+		}
+		if s.HasVariation {
+			b.MagneticVariation = s.MagneticVariation
+		}
+		if !s.HasPosition || !b.acceptsPosition(nmea.TypeRMC, precedence, now) {
+			return false
+		}
+		b.lastRMCFix = now
+		b.PositionFixTime = fixTime
+		b.setFix(s.Latitude, s.Longitude, now)
+		b.GpsQualityIndicator = getGpsQualityFromMode(s.Mode, s.Valid)
+		b.FixValid = s.Valid
+		// RMC speed and course only fill in for a missing or stale VTG
+		if now.Sub(b.lastVTGFix) > positionSourceTimeout {
+			if s.HasSpeed {
+				b.SpeedInKnots = s.SpeedKnots
+			}
+			if s.HasCourse {
+				b.CourseOverGround = s.Course
+			}
+		}
+		b.LastPositionTime = now
+		return true
+	case nmea.ZDA:
+		if s.HasDateTime {
+			b.updateFixTime(s.DateTime, now)
+		}
+	case nmea.HDT:
+		if s.HasHeading {
+			b.applyHeading(s.Heading, s.Heading, nmea.TypeHDT, calibration, now)
+			b.lastHDTFix = now
+		}
+		b.LastHDTTime = now
+	case nmea.HDG:
+		if s.HasVariation {
+			b.MagneticVariation = s.Variation
+		}
+		// A magnetic compass only steers the heading while there is no gyro
+		if s.HasHeading && now.Sub(b.lastHDTFix) > positionSourceTimeout {
+			b.applyHeading(s.Heading, s.Magnetic()+b.MagneticVariation, nmea.TypeHDG, calibration, now)
+		}
+	case nmea.HDM:
+		if s.HasHeading && now.Sub(b.lastHDTFix) > positionSourceTimeout {
+			b.applyHeading(s.Heading, s.Heading+b.MagneticVariation, nmea.TypeHDM, calibration, now)
+		}
+	case nmea.VTG:
+		if s.HasSpeed {
+			b.SpeedInKnots = s.SpeedKnots
+			b.lastVTGFix = now
+		}
+		if s.HasTrueTrack {
+			b.CourseOverGround = s.TrueTrack
+		}
+		b.LastVTGTime = now
+	case nmea.DBT:
+		if s.HasDepth {
+			b.WaterDepth = s.Meters()
+		}
+	case nmea.DPT:
+		if s.HasDepth {
+			b.WaterDepth = s.Depth
+		}
+	case nmea.MTW, nmea.MWV, nmea.MDA, nmea.XDR:
+		b.applyEnvironment(s, now)
+	}
+
+	return false
+}
+
+// position returns the current position in decimal degrees, the dead
+// reckoning estimate while there is one
+func (b *NMEABuffer) position() (float64, float64) {
+	if b.Estimated {
+		return b.estimateLatitude, b.estimateLongitude
+	}
+	return b.fixLatitude, b.fixLongitude
+}
+
+// track returns the course over ground while the vessel makes way and the
+// heading otherwise, when the course of a GNSS receiver is noise
+func (b *NMEABuffer) track() float64 {
+	if b.SpeedInKnots >= minCourseSpeedKnots {
+		return b.CourseOverGround
+	}
+	return b.HeadingDegree
+}
+
+// setFix stores a received position, ending any dead reckoning estimate
+func (b *NMEABuffer) setFix(latitude, longitude float64, now time.Time) {
+	b.Latitude = formatCoordinate(latitude, "N", "S")
+	b.Longitude = formatCoordinate(longitude, "E", "W")
+	b.fixLatitude, b.fixLongitude, b.fixAt = latitude, longitude, now
+	b.Estimated = false
+}
+
+// updateFixTime records the receiver's UTC time and how far behind it arrived
+func (b *NMEABuffer) updateFixTime(fixTime time.Time, now time.Time) {
+	b.FixTime = fixTime
+	b.ClockSkew = now.Sub(fixTime)
+}
+
+// applyHeading stores the raw heading next to the true heading corrected by
+// the vessel's calibration offset
+func (b *NMEABuffer) applyHeading(raw, trueHeading float64, source string, calibration float64, now time.Time) {
+	b.RawHeadingDegree = raw
+	b.HeadingDegree = nmea.NormalizeDegrees(trueHeading + calibration)
+	b.HeadingSource = source
+	b.HeadingCalibration = calibration
+	b.headingAt = now
+}
+
+// acceptsPosition decides whether a position from the given sentence type
+// may overwrite the buffer under the configured precedence
+func (b *NMEABuffer) acceptsPosition(sentenceType string, precedence string, now time.Time) bool {
+	switch precedence {
+	case PrecedenceLatest:
+		return true
+	case PrecedenceRMC:
+		return sentenceType == nmea.TypeRMC || now.Sub(b.lastRMCFix) > positionSourceTimeout
+	default:
+		return sentenceType == nmea.TypeGGA || now.Sub(b.lastGGAFix) > positionSourceTimeout
+	}
+}
+
+// newNMEAParser builds the sentence parser from the tcp.nmea config
+func newNMEAParser() *nmea.Parser {
+	return &nmea.Parser{
+		RequireChecksum:     facades.Config().GetBool("tcp.nmea.require_checksum", false),
+		AllowUnknownTalkers: facades.Config().GetBool("tcp.nmea.allow_unknown_talkers", false),
+	}
+}
+
+// positionPrecedence returns the configured GGA/RMC precedence
+func positionPrecedence() string {
+	switch precedence := facades.Config().GetString("tcp.nmea.position_precedence", PrecedenceGGA); precedence {
+	case PrecedenceGGA, PrecedenceRMC, PrecedenceLatest:
+		return precedence
+	default:
+		facades.Log().Warning(fmt.Sprintf("Unknown NMEA position precedence %q, using %q", precedence, PrecedenceGGA))
+		return PrecedenceGGA
+	}
+}
+
+// formatCoordinate converts signed decimal degrees to the "deg°min°dir"
+// format stored in vessel records
+func formatCoordinate(decimal float64, positive, negative string) string {
+	direction := positive
+	if decimal < 0 {
+		direction = negative
+		decimal = -decimal
+	}
+
+	degrees := math.Floor(decimal)
+	minutes := (decimal - degrees) * 60
+	return fmt.Sprintf("%d°%.4f°%s", int(degrees), minutes, direction)
+}
+
+func getGpsQualityFromIndicator(indicator int) models.GpsQuality {
+	switch indicator {
+	case 0:
+		return models.FixNotValid
+	case 1:
+		return models.GpsFix
+	case 2:
+		return models.DifferentialGpsFix
+	case 3:
+		return models.NotApplicable
+	case 4:
+		return models.RtkFixed
+	case 5:
+		return models.RtkFloat
+	case 6:
+		return models.InsDeadReckoning
+	default:
+		return models.NotApplicable
+	}
+}
+
+// getGpsQualityFromMode maps the RMC status and FAA mode indicator to a GPS quality
+func getGpsQualityFromMode(mode string, valid bool) models.GpsQuality {
+	if !valid {
+		return models.FixNotValid
+	}
+
+	switch mode {
+	case "N":
+		return models.FixNotValid
+	case "D":
+		return models.DifferentialGpsFix
+	case "R":
+		return models.RtkFixed
+	case "F":
+		return models.RtkFloat
+	case "E":
+		return models.InsDeadReckoning
+	default:
+		return models.GpsFix
+	}
+}
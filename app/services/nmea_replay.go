@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"goravel/app/helpers/nmea"
+)
+
+// ReplayEntry is one line of a recorded NMEA log
+type ReplayEntry struct {
+	Time     time.Time // When the line was received or, failing that, its GNSS time; zero if unknown
+	CallSign string    // From a CALLSIGN, prefix, empty otherwise
+	Sentence string
+}
+
+// ReplaySink receives the sentences of a replay. at is the time the line was
+// originally received, zero when the log does not tell.
+type ReplaySink interface {
+	Send(callSign string, sentence string, at time.Time) error
+	Close() error
+}
+
+// ReplayStats summarises a finished replay
+type ReplayStats struct {
+	Lines    int
+	Sent     int
+	Rejected int
+	Duration time.Duration
+}
+
+// OpenReplayLog opens a log for reading, decompressing .gz files such as the
+// day files of the raw archive
+func OpenReplayLog(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, file}, nil
+}
+
+// ParseReplayLine reads a line of the raw archive
+// ("<received at>\t<source>\t<sentence>"), a CALLSIGN, prefixed sentence or
+// a bare sentence. Lines without a receive time take the time of a GGA, RMC
+// or ZDA sentence, resolved against the previous entry's time.
+func ParseReplayLine(line string, previous time.Time) (ReplayEntry, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ReplayEntry{}, false
+	}
+
+	var entry ReplayEntry
+	if fields := strings.SplitN(line, "\t", 3); len(fields) == 3 {
+		if receivedAt, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+			entry.Time = receivedAt
+			line = strings.TrimSpace(fields[2])
+		}
+	}
+
+	if line != "" && line[0] != '$' && line[0] != '!' {
+		callSign, rest, _ := strings.Cut(line, ",")
+		entry.CallSign, line = strings.TrimSpace(callSign), strings.TrimSpace(rest)
+	}
+	if line == "" || (line[0] != '$' && line[0] != '!') {
+		return ReplayEntry{}, false
+	}
+	entry.Sentence = line
+
+	if entry.Time.IsZero() {
+		entry.Time = sentenceTime(line, previous)
+	}
+	return entry, true
+}
+
+// sentenceTime returns the GNSS time carried by a sentence, if any
+func sentenceTime(raw string, previous time.Time) time.Time {
+	sentence, err := nmea.Parse(raw)
+	if err != nil {
+		return time.Time{}
+	}
+
+	switch s := sentence.(type) {
+	case nmea.RMC:
+		if s.HasDateTime {
+			return s.DateTime
+		}
+	case nmea.ZDA:
+		if s.HasDateTime {
+			return s.DateTime
+		}
+	case nmea.GGA:
+		if s.HasTime && !previous.IsZero() {
+			return nmea.ResolveTimeOfDay(s.Time, previous)
+		}
+	}
+	return time.Time{}
+}
+
+// Replay feeds a log into sink, waiting between lines as long as the original
+// gaps divided by speed. A speed of 0 or less sends as fast as possible.
+// callSign overrides the call sign of every line when set.
+func Replay(ctx context.Context, log io.Reader, sink ReplaySink, callSign string, speed float64) (ReplayStats, error) {
+	var stats ReplayStats
+	var previous time.Time
+	started := time.Now()
+
+	scanner := bufio.NewScanner(log)
+	for scanner.Scan() {
+		entry, ok := ParseReplayLine(scanner.Text(), previous)
+		if !ok {
+			continue
+		}
+		stats.Lines++
+
+		if !entry.Time.IsZero() {
+			if gap := entry.Time.Sub(previous); !previous.IsZero() && gap > 0 && speed > 0 {
+				select {
+				case <-ctx.Done():
+					stats.Duration = time.Since(started)
+					return stats, ctx.Err()
+				case <-time.After(time.Duration(float64(gap) / speed)):
+				}
+			}
+			previous = entry.Time
+		}
+
+		if callSign != "" {
+			entry.CallSign = callSign
+		}
+		if entry.CallSign == "" {
+			stats.Rejected++
+			continue
+		}
+
+		if err := sink.Send(entry.CallSign, entry.Sentence, entry.Time); err != nil {
+			var rejected *ReplayRejectedError
+			if !errors.As(err, &rejected) {
+				stats.Duration = time.Since(started)
+				return stats, err
+			}
+			stats.Rejected++
+			continue
+		}
+		stats.Sent++
+	}
+
+	stats.Duration = time.Since(started)
+	// The archive file of the current day ends in an unterminated gzip member
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// ReplayRejectedError is returned by a sink for a line it dropped; the
+// replay counts it and carries on
+type ReplayRejectedError struct {
+	Reason string
+}
+
+func (e *ReplayRejectedError) Error() string {
+	return "rejected: " + e.Reason
+}
+
+// LocalReplaySink processes sentences in this process with the same parser
+// and record creation as the TCP listener, writing to the configured
+// database. Point DB_DATABASE at a scratch database to replay in isolation.
+// The pipeline runs on the original receive times, so record spacing and
+// created_at follow the capture whatever the playback speed.
+type LocalReplaySink struct {
+	vessels    *TCPVesselService
+	aisService *AISService
+	clock      time.Time // Time of the last line that had one
+}
+
+// NewLocalReplaySink creates a sink with its own vessel buffers
+func NewLocalReplaySink() *LocalReplaySink {
+	aisService := NewAISService()
+	aisService.Start()
+	return &LocalReplaySink{
+		vessels:    NewTCPVesselService(aisService, nil, nil),
+		aisService: aisService,
+	}
+}
+
+func (s *LocalReplaySink) Send(callSign string, sentence string, at time.Time) error {
+	// Lines without a time of their own share the time of the line before
+	if !at.IsZero() {
+		s.clock = at
+	} else if s.clock.IsZero() {
+		s.clock = time.Now()
+	}
+
+	kapal, err := s.vessels.getKapal(callSign)
+	if err != nil {
+		return &ReplayRejectedError{Reason: lookupRejectReason(err)}
+	}
+	if reason := s.vessels.processVesselData(*kapal, SourceReplay, sentence, s.clock); reason != "" {
+		return &ReplayRejectedError{Reason: reason}
+	}
+	return nil
+}
+
+func (s *LocalReplaySink) Close() error {
+	s.aisService.Stop()
+	return nil
+}
+
+// TCPReplaySink sends sentences to a running navigation listener, so a replay
+// reaches live clients exactly like a vessel would
+type TCPReplaySink struct {
+	conn     net.Conn
+	token    string
+	callSign string
+}
+
+// NewTCPReplaySink connects to the navigation listener at address. token is
+// sent in the handshake when the listener requires authentication.
+func NewTCPReplaySink(address string, token string) (*TCPReplaySink, error) {
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discard echoes and ACK lines so the listener never blocks on writing
+	go io.Copy(io.Discard, conn)
+
+	return &TCPReplaySink{conn: conn, token: token}, nil
+}
+
+func (s *TCPReplaySink) Send(callSign string, sentence string, at time.Time) error {
+	line := sentence
+	if callSign != s.callSign {
+		if s.token != "" && s.callSign == "" {
+			if _, err := fmt.Fprintf(s.conn, "%s,%s\n", callSign, s.token); err != nil {
+				return err
+			}
+		} else {
+			line = callSign + "," + sentence
+		}
+		s.callSign = callSign
+	}
+
+	_, err := io.WriteString(s.conn, line+"\n")
+	return err
+}
+
+func (s *TCPReplaySink) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goravel/app/helpers/geo"
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Reasons a position fix is rejected by the plausibility filter
+const (
+	FixRejectInvalid = "invalid" // No fix, or the 0,0 fallback of a receiver without one
+	FixRejectSpeed   = "speed"   // Implied speed from the last accepted fix is above the vessel maximum
+	FixRejectLand    = "land"    // Inside a polygon of the land mask
+)
+
+// minFixInterval keeps two fixes of the same epoch, such as a GGA and an RMC,
+// from implying an infinite speed
+const minFixInterval = 1 * time.Second
+
+// positionFix is the position carried by a GGA or RMC sentence
+type positionFix struct {
+	Latitude  float64
+	Longitude float64
+	Valid     bool
+	FixTime   time.Time // Zero when the sentence had no UTC time
+}
+
+// positionFixOf returns the position of a sentence, if it carries one
+func positionFixOf(sentence nmea.Sentence) (positionFix, bool) {
+	switch s := sentence.(type) {
+	case nmea.GGA:
+		if s.HasPosition {
+			return positionFix{Latitude: s.Latitude, Longitude: s.Longitude, Valid: s.FixQuality > 0}, true
+		}
+	case nmea.RMC:
+		if s.HasPosition {
+			fix := positionFix{Latitude: s.Latitude, Longitude: s.Longitude, Valid: s.Valid}
+			if s.HasDateTime {
+				fix.FixTime = s.DateTime
+			}
+			return fix, true
+		}
+	}
+	return positionFix{}, false
+}
+
+// PositionFilterStats counts the fixes of one vessel
+type PositionFilterStats struct {
+	CallSign       string            `json:"call_sign"`
+	Accepted       uint64            `json:"accepted"`
+	Rejected       map[string]uint64 `json:"rejected"` // By reason
+	LastReason     string            `json:"last_reason,omitempty"`
+	LastRejectedAt *time.Time        `json:"last_rejected_at"`
+}
+
+// PositionFilter rejects position fixes that cannot be right before they
+// reach a vessel's buffer: fixes the receiver marks invalid, jumps faster
+// than the vessel can sail and positions on land. After reanchorAfter
+// consecutive speed rejections the next fix is accepted as the new reference,
+// so one bad fix that slipped through cannot block a vessel for good.
+type PositionFilter struct {
+	rejectInvalid bool
+	maxSpeedKnots float64 // Default for vessels without their own, 0 disables the check
+	reanchorAfter int
+	keepRejected  bool
+	land          *geo.LandMask
+
+	mutex   sync.Mutex
+	vessels map[string]*vesselFixes // key is CallSign
+}
+
+// vesselFixes is the reference fix and counters of one vessel
+type vesselFixes struct {
+	last          positionFix
+	lastAt        time.Time // When the reference fix was taken
+	speedRejected int       // Consecutive speed rejections
+	stats         PositionFilterStats
+}
+
+// NewPositionFilter creates the filter from the tcp.plausibility config section
+func NewPositionFilter() *PositionFilter {
+	filter := &PositionFilter{
+		rejectInvalid: facades.Config().GetBool("tcp.plausibility.reject_invalid", true),
+		maxSpeedKnots: float64(facades.Config().GetInt("tcp.plausibility.max_speed_knots", 60)),
+		reanchorAfter: facades.Config().GetInt("tcp.plausibility.reanchor_after", 10),
+		keepRejected:  facades.Config().GetBool("tcp.plausibility.keep_rejected", false),
+		vessels:       make(map[string]*vesselFixes),
+	}
+
+	if path := facades.Config().GetString("tcp.plausibility.land_file", ""); path != "" {
+		land, err := geo.LoadLandMask(path)
+		if err != nil {
+			facades.Log().Error(fmt.Sprintf("Failed to load land mask %s, positions on land are accepted: %v", path, err))
+		} else {
+			filter.land = land
+			facades.Log().Info(fmt.Sprintf("🗺️ Rejecting positions inside %d land polygons", len(land.Polygons)))
+		}
+	}
+	return filter
+}
+
+// Check judges a fix of a vessel and returns the reject reason, empty when
+// the fix is plausible and becomes the new reference
+func (f *PositionFilter) Check(kapal models.Kapal, source string, fix positionFix, now time.Time) string {
+	f.mutex.Lock()
+	vessel, exists := f.vessels[kapal.CallSign]
+	if !exists {
+		vessel = &vesselFixes{stats: PositionFilterStats{CallSign: kapal.CallSign, Rejected: make(map[string]uint64)}}
+		f.vessels[kapal.CallSign] = vessel
+	}
+
+	reason, impliedKnots := f.judge(kapal, vessel, fix, now)
+	if reason == "" {
+		vessel.last, vessel.lastAt = fix, now
+		vessel.stats.Accepted++
+		f.mutex.Unlock()
+		return ""
+	}
+
+	vessel.stats.Rejected[reason]++
+	vessel.stats.LastReason = reason
+	vessel.stats.LastRejectedAt = &now
+	f.mutex.Unlock()
+
+	facades.Log().Debug(fmt.Sprintf("Rejected %s fix of %s from %s: %.5f, %.5f", reason, kapal.CallSign, source, fix.Latitude, fix.Longitude))
+	if f.keepRejected {
+		f.storeRejected(kapal.CallSign, source, fix, reason, impliedKnots, now)
+	}
+	return reason
+}
+
+// judge applies the checks in order of cost. The caller holds the mutex.
+func (f *PositionFilter) judge(kapal models.Kapal, vessel *vesselFixes, fix positionFix, now time.Time) (string, *float64) {
+	if f.rejectInvalid && (!fix.Valid || (fix.Latitude == 0 && fix.Longitude == 0)) {
+		return FixRejectInvalid, nil
+	}
+
+	maxSpeed := kapal.MaxSpeedKnots
+	if maxSpeed <= 0 {
+		maxSpeed = f.maxSpeedKnots
+	}
+	if maxSpeed > 0 && !vessel.lastAt.IsZero() {
+		elapsed := now.Sub(vessel.lastAt)
+		if !fix.FixTime.IsZero() && !vessel.last.FixTime.IsZero() {
+			elapsed = fix.FixTime.Sub(vessel.last.FixTime)
+		}
+		if elapsed < minFixInterval {
+			elapsed = minFixInterval
+		}
+
+		distance := geo.DistanceMetres(vessel.last.Latitude, vessel.last.Longitude, fix.Latitude, fix.Longitude)
+		knots := distance / geo.MetresPerNauticalMile / elapsed.Hours()
+		if knots > maxSpeed {
+			vessel.speedRejected++
+			if f.reanchorAfter <= 0 || vessel.speedRejected <= f.reanchorAfter {
+				return FixRejectSpeed, &knots
+			}
+			facades.Log().Warning(fmt.Sprintf("%s: %d consecutive fixes implied more than %.0f knots, taking %.5f, %.5f as the new reference",
+				kapal.CallSign, vessel.speedRejected-1, maxSpeed, fix.Latitude, fix.Longitude))
+		}
+	}
+	vessel.speedRejected = 0
+
+	if f.land.Contains(fix.Latitude, fix.Longitude) {
+		return FixRejectLand, nil
+	}
+	return "", nil
+}
+
+// storeRejected keeps a rejected fix for audit
+func (f *PositionFilter) storeRejected(callSign string, source string, fix positionFix, reason string, impliedKnots *float64, now time.Time) {
+	record := &models.RejectedFix{
+		CallSign:          callSign,
+		Source:            source,
+		Latitude:          fix.Latitude,
+		Longitude:         fix.Longitude,
+		Reason:            reason,
+		ImpliedSpeedKnots: impliedKnots,
+		ReceivedAt:        now,
+	}
+	if !fix.FixTime.IsZero() {
+		fixTime := fix.FixTime
+		record.FixTime = &fixTime
+	}
+	if err := facades.Orm().Query().Create(record); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to store rejected fix of %s: %v", callSign, err))
+	}
+}
+
+// Stats returns the counters of every vessel, by call sign
+func (f *PositionFilter) Stats() []PositionFilterStats {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats := make([]PositionFilterStats, 0, len(f.vessels))
+	for _, vessel := range f.vessels {
+		entry := vessel.stats
+		entry.Rejected = make(map[string]uint64, len(vessel.stats.Rejected))
+		for reason, count := range vessel.stats.Rejected {
+			entry.Rejected[reason] = count
+		}
+		stats = append(stats, entry)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].CallSign < stats[j].CallSign })
+	return stats
+}
+
+// GetPositionFilterStats returns the plausibility counters of every vessel
+func (s *TCPVesselService) GetPositionFilterStats() []PositionFilterStats {
+	return s.plausibility.Stats()
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// RecordWriter takes vessel, telemetry and sensor records off the ingest
+// goroutines and inserts them in batches, one queue per table. SeriesIDs are
+// counted in memory, so a vessel record no longer needs a MAX(id) query
+// first. A full queue blocks the producer until the next flush, which slows
+// the senders down instead of growing memory without bound, so producers
+// must not hold a buffer mutex while they write. A nil or
+// disabled writer inserts every record on the spot, as before. A batch the
+// database refuses is queued again with a growing backoff and dropped only
+// once it has failed maxRetries more times.
+type RecordWriter struct {
+	enabled       bool
+	batchSize     int
+	capacity      int // Per queue, counting batches waiting for a retry
+	flushInterval time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration // Before the first retry, doubled for every further one
+
+	mutex      sync.Mutex
+	space      *sync.Cond // Signalled when a flush empties a queue
+	flushMutex sync.Mutex // Serialises flushes from the loop, Flush and Stop
+	stopped    bool
+	wakeChan   chan struct{}
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+	seriesIDs  map[string]uint64 // Last SeriesID by CallSign
+
+	vesselRecords    *writeQueue[models.VesselRecord]
+	telemetryRecords *writeQueue[models.VesselTelemetryRecord]
+	sensorRecords    *writeQueue[models.SensorRecord]
+}
+
+// WriteQueueStats reports the state of one table's queue
+type WriteQueueStats struct {
+	Table         string     `json:"table"`
+	Depth         int        `json:"depth"`
+	Capacity      int        `json:"capacity"`
+	Written       uint64     `json:"written"`
+	Retrying      int        `json:"retrying"` // Records of refused batches waiting for another attempt
+	Retried       uint64     `json:"retried"`  // Records queued again after a refused insert
+	Failed        uint64     `json:"failed"`   // Records dropped once their batch ran out of retries
+	Batches       uint64     `json:"batches"`
+	Blocked       uint64     `json:"blocked"` // Producers that waited for a full queue
+	LastFlushAt   *time.Time `json:"last_flush_at"`
+	LastFlushMs   int64      `json:"last_flush_ms"`   // Duration of the last batch insert
+	LastLatencyMs int64      `json:"last_latency_ms"` // How long the oldest record of the last batch waited to be written
+}
+
+// writeQueue holds the pending records of one table. Its fields are guarded
+// by the writer mutex.
+type writeQueue[T any] struct {
+	writer   *RecordWriter
+	items    []T
+	oldestAt time.Time
+	retries  []failedBatch[T]
+	stats    WriteQueueStats
+}
+
+// failedBatch is a batch the database refused, waiting for its next attempt
+type failedBatch[T any] struct {
+	items    []T
+	oldestAt time.Time
+	attempts int
+	retryAt  time.Time
+}
+
+// maxRetryBackoff caps the doubling wait between attempts of a batch
+const maxRetryBackoff = time.Minute
+
+// NewRecordWriter creates the writer from the tcp.write_behind config section
+func NewRecordWriter() *RecordWriter {
+	w := &RecordWriter{
+		enabled:       facades.Config().GetBool("tcp.write_behind.enabled", true),
+		batchSize:     facades.Config().GetInt("tcp.write_behind.batch_size", 500),
+		capacity:      facades.Config().GetInt("tcp.write_behind.capacity", 10000),
+		flushInterval: time.Duration(facades.Config().GetInt("tcp.write_behind.flush_interval_ms", 1000)) * time.Millisecond,
+		maxRetries:    facades.Config().GetInt("tcp.write_behind.max_retries", 5),
+		retryBackoff:  time.Duration(facades.Config().GetInt("tcp.write_behind.retry_backoff_ms", 1000)) * time.Millisecond,
+		wakeChan:      make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+		seriesIDs:     make(map[string]uint64),
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = 500
+	}
+	if w.capacity < w.batchSize {
+		w.capacity = w.batchSize
+	}
+	if w.maxRetries < 0 {
+		w.maxRetries = 0
+	}
+	if w.retryBackoff <= 0 {
+		w.retryBackoff = time.Second
+	}
+	w.space = sync.NewCond(&w.mutex)
+	w.vesselRecords = newWriteQueue[models.VesselRecord](w, "vessel_records")
+	w.telemetryRecords = newWriteQueue[models.VesselTelemetryRecord](w, "vessel_telemetry_records")
+	w.sensorRecords = newWriteQueue[models.SensorRecord](w, "sensor_records")
+	return w
+}
+
+func newWriteQueue[T any](w *RecordWriter, table string) *writeQueue[T] {
+	return &writeQueue[T]{writer: w, stats: WriteQueueStats{Table: table, Capacity: w.capacity}}
+}
+
+// Enabled reports whether records are batched
+func (w *RecordWriter) Enabled() bool {
+	return w != nil && w.enabled
+}
+
+// Start flushes the queues every flush interval, or sooner when one holds a
+// full batch. It returns when Stop is called.
+func (w *RecordWriter) Start() {
+	if !w.Enabled() {
+		return
+	}
+	facades.Log().Info(fmt.Sprintf("📝 Batching record inserts, up to %d per batch every %v", w.batchSize, w.flushInterval))
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+		case <-w.wakeChan:
+		}
+		w.Flush()
+	}
+}
+
+// WriteVesselRecord numbers a vessel record in its series and stores it. The
+// first record of a vessel since startup looks up its last SeriesID.
+func (w *RecordWriter) WriteVesselRecord(record models.VesselRecord) {
+	if !w.Enabled() {
+		record.SeriesID = lastSeriesID(record.CallSign) + 1
+		insertRecords([]models.VesselRecord{record}, "vessel_records")
+		return
+	}
+
+	w.mutex.Lock()
+	seriesID, known := w.seriesIDs[record.CallSign]
+	w.mutex.Unlock()
+	if !known {
+		// Only the first record of a vessel since startup asks the database
+		seriesID = lastSeriesID(record.CallSign)
+	}
+
+	w.mutex.Lock()
+	if current := w.seriesIDs[record.CallSign]; current > seriesID {
+		seriesID = current
+	}
+	seriesID++
+	w.seriesIDs[record.CallSign] = seriesID
+	w.mutex.Unlock()
+
+	record.SeriesID = seriesID
+	w.vesselRecords.push(record)
+}
+
+// WriteTelemetryRecords stores environment readings of a vessel
+func (w *RecordWriter) WriteTelemetryRecords(records []models.VesselTelemetryRecord) {
+	if !w.Enabled() {
+		insertRecords(records, "vessel_telemetry_records")
+		return
+	}
+	for _, record := range records {
+		w.telemetryRecords.push(record)
+	}
+}
+
+// WriteSensorRecord stores a sensor reading
+func (w *RecordWriter) WriteSensorRecord(record models.SensorRecord) {
+	if !w.Enabled() {
+		insertRecords([]models.SensorRecord{record}, "sensor_records")
+		return
+	}
+	w.sensorRecords.push(record)
+}
+
+// Flush writes everything queued so far
+func (w *RecordWriter) Flush() {
+	if !w.Enabled() {
+		return
+	}
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+
+	w.vesselRecords.flush()
+	w.telemetryRecords.flush()
+	w.sensorRecords.flush()
+}
+
+// Stats returns the state of every queue
+func (w *RecordWriter) Stats() []WriteQueueStats {
+	if !w.Enabled() {
+		return []WriteQueueStats{}
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return []WriteQueueStats{
+		w.vesselRecords.snapshot(),
+		w.telemetryRecords.snapshot(),
+		w.sensorRecords.snapshot(),
+	}
+}
+
+// Stop writes the remaining records, retrying refused batches once more
+// without waiting for their backoff; records written afterwards are inserted
+// directly
+func (w *RecordWriter) Stop() {
+	if !w.Enabled() {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stopChan) })
+
+	w.mutex.Lock()
+	w.stopped = true
+	w.space.Broadcast()
+	w.mutex.Unlock()
+
+	w.Flush()
+}
+
+// wake asks the flush loop for an early flush
+func (w *RecordWriter) wake() {
+	select {
+	case w.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// push queues a record, waiting while the queue is full
+func (q *writeQueue[T]) push(item T) {
+	w := q.writer
+	w.mutex.Lock()
+	if q.pending() >= w.capacity && !w.stopped {
+		q.stats.Blocked++
+		w.wake()
+		for q.pending() >= w.capacity && !w.stopped {
+			w.space.Wait()
+		}
+	}
+	if w.stopped {
+		w.mutex.Unlock()
+		insertRecords([]T{item}, q.stats.Table)
+		return
+	}
+
+	if len(q.items) == 0 {
+		q.oldestAt = time.Now()
+	}
+	q.items = append(q.items, item)
+	full := len(q.items) >= w.batchSize
+	w.mutex.Unlock()
+
+	if full {
+		w.wake()
+	}
+}
+
+// flush retries the refused batches that are due, then inserts the queued
+// records in batches of batchSize. The caller holds the flush mutex.
+func (q *writeQueue[T]) flush() {
+	w := q.writer
+	started := time.Now()
+	w.mutex.Lock()
+	stopping := w.stopped
+	var due []failedBatch[T]
+	waiting := q.retries[:0]
+	for _, batch := range q.retries {
+		if stopping || !batch.retryAt.After(started) {
+			due = append(due, batch)
+		} else {
+			waiting = append(waiting, batch)
+		}
+	}
+	q.retries = waiting
+	items := q.items
+	q.items = nil
+	for start := 0; start < len(items); start += w.batchSize {
+		end := min(start+w.batchSize, len(items))
+		due = append(due, failedBatch[T]{items: items[start:end], oldestAt: q.oldestAt})
+	}
+	w.space.Broadcast()
+	w.mutex.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	var written, retried, failed uint64
+	var batches uint64
+	var refused []failedBatch[T]
+	oldestAt := due[0].oldestAt
+	for _, batch := range due {
+		batches++
+		if batch.oldestAt.Before(oldestAt) {
+			oldestAt = batch.oldestAt
+		}
+		if insertRecords(batch.items, q.stats.Table) {
+			written += uint64(len(batch.items))
+			continue
+		}
+
+		batch.attempts++
+		if stopping || batch.attempts > w.maxRetries {
+			failed += uint64(len(batch.items))
+			facades.Log().Error(fmt.Sprintf("Dropped a batch of %d %s after %d attempts", len(batch.items), q.stats.Table, batch.attempts))
+			continue
+		}
+		backoff := min(w.retryBackoff<<(batch.attempts-1), maxRetryBackoff)
+		batch.retryAt = time.Now().Add(backoff)
+		retried += uint64(len(batch.items))
+		refused = append(refused, batch)
+		facades.Log().Warning(fmt.Sprintf("Retrying a batch of %d %s in %v, attempt %d of %d", len(batch.items), q.stats.Table, backoff, batch.attempts+1, w.maxRetries+1))
+	}
+	finished := time.Now()
+
+	w.mutex.Lock()
+	q.retries = append(q.retries, refused...)
+	q.stats.Written += written
+	q.stats.Retried += retried
+	q.stats.Failed += failed
+	q.stats.Batches += batches
+	q.stats.LastFlushAt = &finished
+	q.stats.LastFlushMs = finished.Sub(started).Milliseconds()
+	q.stats.LastLatencyMs = finished.Sub(oldestAt).Milliseconds()
+	w.mutex.Unlock()
+}
+
+// pending counts the records the queue holds, including those waiting for a
+// retry. The caller holds the writer mutex.
+func (q *writeQueue[T]) pending() int {
+	pending := len(q.items)
+	for _, batch := range q.retries {
+		pending += len(batch.items)
+	}
+	return pending
+}
+
+// snapshot copies the stats of the queue. The caller holds the writer mutex.
+func (q *writeQueue[T]) snapshot() WriteQueueStats {
+	stats := q.stats
+	stats.Depth = len(q.items)
+	stats.Retrying = q.pending() - stats.Depth
+	return stats
+}
+
+// insertRecords inserts records of one table in a single statement and
+// reports whether it succeeded
+func insertRecords[T any](records []T, table string) bool {
+	if len(records) == 0 {
+		return true
+	}
+	started := time.Now()
+	err := facades.Orm().Query().Create(&records)
+	observeWrite(table, len(records), time.Since(started), err == nil)
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to insert %d %s: %v", len(records), table, err))
+		return false
+	}
+	return true
+}
+
+// lastSeriesID returns the SeriesID of the newest record of a vessel, 0 when
+// it has none
+func lastSeriesID(callSign string) uint64 {
+	var lastRecord models.VesselRecord
+	err := facades.Orm().Query().
+		Where("call_sign = ?", callSign).
+		Where("id = (SELECT MAX(id) FROM vessel_records WHERE call_sign = ?)", callSign).
+		First(&lastRecord)
+	if err != nil {
+		return 0
+	}
+	return lastRecord.SeriesID
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"goravel/app/helpers/signalk"
+	"goravel/app/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+const (
+	signalKSourceRefresh = 1 * time.Minute
+	signalKRetryMin      = 5 * time.Second
+	signalKRetryMax      = 1 * time.Minute
+)
+
+// SignalKSourceStatus describes the connection to one Signal K source
+type SignalKSourceStatus struct {
+	ID          uint       `json:"id"`
+	CallSign    string     `json:"call_sign"`
+	URL         string     `json:"url"`
+	Connected   bool       `json:"connected"`
+	Deltas      uint64     `json:"deltas"`
+	LastDeltaAt *time.Time `json:"last_delta_at"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+type signalKSession struct {
+	source models.SignalKSource
+	cancel context.CancelFunc
+	status SignalKSourceStatus
+}
+
+// SignalKService streams deltas from the configured Signal K sources into
+// the vessel pipeline and builds Signal K deltas of the fleet state
+type SignalKService struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	vessels *TCPVesselService
+
+	mutex    sync.Mutex
+	sessions map[uint]*signalKSession // key is SignalKSource ID
+}
+
+// NewSignalKService creates a Signal K service feeding the given vessel service
+func NewSignalKService(vessels *TCPVesselService) *SignalKService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SignalKService{
+		ctx:      ctx,
+		cancel:   cancel,
+		vessels:  vessels,
+		sessions: make(map[uint]*signalKSession),
+	}
+}
+
+// Start connects to the enabled sources and keeps following configuration changes
+func (s *SignalKService) Start() {
+	s.updateSessions()
+
+	go func() {
+		ticker := time.NewTicker(signalKSourceRefresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.updateSessions()
+			}
+		}
+	}()
+}
+
+// Stop disconnects from every source
+func (s *SignalKService) Stop() {
+	s.cancel()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, session := range s.sessions {
+		session.cancel()
+		delete(s.sessions, id)
+	}
+}
+
+// updateSessions starts sessions for new or changed sources and stops the
+// ones that were removed or disabled
+func (s *SignalKService) updateSessions() {
+	var sources []models.SignalKSource
+	if err := facades.Orm().Query().Where("enabled = ?", true).Find(&sources); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to load Signal K sources: %v", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wanted := make(map[uint]models.SignalKSource, len(sources))
+	for _, source := range sources {
+		wanted[source.ID] = source
+	}
+
+	for id, session := range s.sessions {
+		source, exists := wanted[id]
+		if !exists || source.URL != session.source.URL || source.CallSign != session.source.CallSign {
+			session.cancel()
+			delete(s.sessions, id)
+		}
+	}
+
+	for id, source := range wanted {
+		if _, exists := s.sessions[id]; exists {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(s.ctx)
+		session := &signalKSession{
+			source: source,
+			cancel: cancel,
+			status: SignalKSourceStatus{ID: source.ID, CallSign: source.CallSign, URL: source.URL},
+		}
+		s.sessions[id] = session
+		go s.runSession(ctx, session)
+	}
+}
+
+// runSession streams from one source, reconnecting with backoff until cancelled
+func (s *SignalKService) runSession(ctx context.Context, session *signalKSession) {
+	retryDelay := signalKRetryMin
+	source := session.source
+
+	for ctx.Err() == nil {
+		facades.Log().Info(fmt.Sprintf("🔌 Connecting to Signal K source %s for %s", source.URL, source.CallSign))
+
+		err := signalk.Stream(ctx, source.URL, func(delta signalk.Delta) {
+			s.setStatus(session, func(status *SignalKSourceStatus) {
+				now := time.Now()
+				status.Connected = true
+				status.Deltas++
+				status.LastDeltaAt = &now
+				status.LastError = ""
+			})
+			retryDelay = signalKRetryMin
+			s.handleDelta(source.CallSign, sourceName(SourceSignalK, source.ID), delta)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.setStatus(session, func(status *SignalKSourceStatus) {
+			status.Connected = false
+			status.LastError = err.Error()
+		})
+		facades.Log().Warning(fmt.Sprintf("⚠️ Signal K source %s for %s: %v, retrying in %v", source.URL, source.CallSign, err, retryDelay))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+		if retryDelay < signalKRetryMax {
+			retryDelay *= 2
+		}
+	}
+}
+
+// handleDelta translates a delta and feeds the sentences into the vessel pipeline
+func (s *SignalKService) handleDelta(callSign string, source string, delta signalk.Delta) {
+	kapal, err := s.vessels.getKapal(callSign)
+	if err != nil {
+		return
+	}
+
+	s.vessels.trackVessel(kapal)
+	for _, sentence := range signalk.Translate(delta) {
+		s.vessels.processSentence(*kapal, source, sentence, time.Now())
+	}
+}
+
+func (s *SignalKService) setStatus(session *signalKSession, update func(*SignalKSourceStatus)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	update(&session.status)
+}
+
+// GetSourceStatus returns the connection state of every running source
+func (s *SignalKService) GetSourceStatus() []SignalKSourceStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make([]SignalKSourceStatus, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		statuses = append(statuses, session.status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
+
+// FleetDeltas returns the current state of every vessel with a buffer as
+// Signal K deltas, one per vessel, with the context vessels.<call sign>
+func (s *SignalKService) FleetDeltas() []signalk.Delta {
+	s.vessels.bufferMutex.Lock()
+	defer s.vessels.bufferMutex.Unlock()
+
+	deltas := make([]signalk.Delta, 0, len(s.vessels.nmeaBuffers))
+	for callSign, buffer := range s.vessels.nmeaBuffers {
+		buffer.mutex.Lock()
+		delta, ok := bufferToDelta(callSign, buffer)
+		buffer.mutex.Unlock()
+
+		if ok {
+			deltas = append(deltas, delta)
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Context < deltas[j].Context
+	})
+	return deltas
+}
+
+// bufferToDelta converts a vessel buffer into a Signal K delta in SI units.
+// The caller must hold the buffer mutex.
+func bufferToDelta(callSign string, buffer *NMEABuffer) (signalk.Delta, bool) {
+	if buffer.Latitude == "" || buffer.Longitude == "" {
+		return signalk.Delta{}, false
+	}
+
+	latitude, _ := models.ParseCoordinate(buffer.Latitude)
+	longitude, _ := models.ParseCoordinate(buffer.Longitude)
+
+	timestamp := buffer.PositionFixTime
+	if timestamp.IsZero() {
+		timestamp = buffer.LastPositionTime
+	}
+
+	values := []signalk.Value{
+		signalk.NewValue(signalk.PathPosition, signalk.Position{Latitude: latitude, Longitude: longitude}),
+		signalk.NewValue(signalk.PathHeadingTrue, signalk.Radians(buffer.HeadingDegree)),
+		signalk.NewValue(signalk.PathSpeedOverGround, signalk.MetresPerSecond(buffer.SpeedInKnots)),
+		signalk.NewValue(signalk.PathCourseOverGround, signalk.Radians(buffer.CourseOverGround)),
+		signalk.NewValue(signalk.PathDepthTransducer, buffer.WaterDepth),
+	}
+
+	return signalk.Delta{
+		Context: "vessels." + callSign,
+		Updates: []signalk.Update{{
+			Source:    &signalk.Source{Label: "binav-avts"},
+			Timestamp: timestamp.UTC(),
+			Values:    values,
+		}},
+	}, true
+}
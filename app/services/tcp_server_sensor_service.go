@@ -29,13 +29,26 @@ type TCPSensorService struct {
 	ConnectionStatus  bool
 	activeConnections int
 	connMutex         sync.Mutex
+	writer            *RecordWriter
+	cacheMutex        sync.Mutex
+	cache             map[string]*sensorCacheEntry // key is sensor ID
 }
 
-// NewTCPSensorService creates a new TCP sensor service
-func NewTCPSensorService() *TCPSensorService {
+// sensorCacheEntry is a sensor lookup, kept for cacheDuration like the
+// vessel lookups
+type sensorCacheEntry struct {
+	found     bool
+	timestamp time.Time
+}
+
+// NewTCPSensorService creates a new TCP sensor service. A nil writer inserts
+// records directly.
+func NewTCPSensorService(writer *RecordWriter) *TCPSensorService {
 	return &TCPSensorService{
 		sensorBuffers: make(map[string]*SensorBuffer),
 		activeSensors: make(map[string]*models.Sensor),
+		writer:        writer,
+		cache:         make(map[string]*sensorCacheEntry),
 	}
 }
 
@@ -57,7 +70,7 @@ func (s *TCPSensorService) Start() error {
 	port := facades.Config().GetString("tcp.sensor.port", "8085")
 	address := fmt.Sprintf("%s:%s", host, port)
 
-	listener, err := net.Listen("tcp", address)
+	listener, err := listen("sensor", address)
 	if err != nil {
 		s.ConnectionStatus = false
 		facades.Log().Error("Failed to start TCP server", err)
@@ -98,6 +111,21 @@ func (s *TCPSensorService) handleConnection(conn net.Conn) {
 		s.decrementConnections()
 	}()
 
+	// With mutual TLS the certificate's common name is the sensor ID
+	boundSensorID, err := peerCommonName(conn)
+	if err != nil {
+		facades.Log().Warning(fmt.Sprintf("Rejected sensor connection from %s: TLS handshake failed: %v", conn.RemoteAddr(), err))
+		return
+	}
+	if boundSensorID != "" {
+		var count int64
+		if err := facades.Orm().Query().Model(&models.Sensor{}).Where("id = ?", boundSensorID).Count(&count); err != nil || count == 0 {
+			facades.Log().Warning(fmt.Sprintf("Rejected sensor connection from %s: certificate CN %s is not a known sensor", conn.RemoteAddr(), boundSensorID))
+			return
+		}
+		facades.Log().Info(fmt.Sprintf("Sensor connection from %s authenticated as %s", conn.RemoteAddr(), boundSensorID))
+	}
+
 	var dataBuffer strings.Builder
 	buffer := make([]byte, 1024)
 
@@ -113,7 +141,7 @@ func (s *TCPSensorService) handleConnection(conn net.Conn) {
 			messages := strings.Split(data, "\n")
 			for _, msg := range messages[:len(messages)-1] {
 				if msg = strings.TrimSpace(msg); msg != "" {
-					s.processMessage(msg)
+					s.processMessage(msg, boundSensorID)
 				}
 			}
 			dataBuffer.Reset()
@@ -161,26 +189,43 @@ func extractTimestamp(rawData string) (time.Time, error) {
 	return timestamp, nil
 }
 
-// processMessage handles an incoming sensor message
-func (s *TCPSensorService) processMessage(msg string) {
+// processMessage handles an incoming sensor message. boundSensorID is the
+// sensor a client certificate identified the connection as, if any; such a
+// connection may only report for that sensor.
+func (s *TCPSensorService) processMessage(msg string, boundSensorID string) {
 	re := regexp.MustCompile(`ID:(\d+)`)
 	matches := re.FindStringSubmatch(msg)
 
-	if len(matches) < 2 {
+	var sensorID string
+	switch {
+	case len(matches) >= 2 && boundSensorID != "" && matches[1] != boundSensorID:
+		facades.Log().Warning(fmt.Sprintf("Rejected message for sensor %s on a connection authenticated as %s", matches[1], boundSensorID))
+		return
+	case len(matches) >= 2:
+		sensorID = matches[1]
+	case boundSensorID != "":
+		sensorID = boundSensorID
+	default:
 		facades.Log().Error("Could not extract sensor ID from message")
 		return
 	}
 
-	sensorID := matches[1]
-
-	var sensor models.Sensor
-	// We should apply the soft-delete filter here if the Sensor model uses soft deletes
-	err := facades.Orm().Query().Where("id = ?", sensorID).FirstOrFail(&sensor)
-	if err != nil {
+	if !s.sensorExists(sensorID) {
 		facades.Log().Error(fmt.Sprintf("Sensor not found: %s", sensorID))
 		return
 	}
+	metricSensorMessages.Inc(sensorID)
 
+	// Queued after the buffer mutex is released, a full write queue waits on
+	// the database
+	if record := s.updateBuffer(sensorID, msg); record != nil {
+		s.writer.WriteSensorRecord(*record)
+	}
+}
+
+// updateBuffer stores a message in the buffer of a sensor and returns the
+// record that became due, nil when none did
+func (s *TCPSensorService) updateBuffer(sensorID string, msg string) *models.SensorRecord {
 	buffer := s.getOrCreateBuffer(sensorID)
 	buffer.mutex.Lock()
 	defer buffer.mutex.Unlock()
@@ -188,30 +233,46 @@ func (s *TCPSensorService) processMessage(msg string) {
 	buffer.RawData = msg
 	buffer.LastUpdateTime = time.Now()
 
-	if time.Since(buffer.LastRecordTime) >= time.Second {
-		// Extract timestamp from raw data
-		timestamp, err := extractTimestamp(buffer.RawData)
-		if err != nil {
-			facades.Log().Warning(fmt.Sprintf("Could not extract timestamp from sensor data: %v. Using current time instead.", err))
-			timestamp = time.Now()
-		}
+	if time.Since(buffer.LastRecordTime) < time.Second {
+		return nil
+	}
 
-		record := &models.SensorRecord{
-			IDSensor:  sensorID,
-			RawData:   buffer.RawData,
-			CreatedAt: timestamp, // Use the extracted timestamp for created_at
-			UpdatedAt: timestamp, // Use the same timestamp for updated_at
-		}
+	// Extract timestamp from raw data
+	timestamp, err := extractTimestamp(buffer.RawData)
+	if err != nil {
+		facades.Log().Warning(fmt.Sprintf("Could not extract timestamp from sensor data: %v. Using current time instead.", err))
+		timestamp = time.Now()
+	}
+	buffer.LastRecordTime = time.Now()
 
-		err = facades.Orm().Query().Create(record)
-		if err != nil {
-			facades.Log().Error(fmt.Sprintf("Failed to create sensor record: %v", err))
-			return
-		}
+	return &models.SensorRecord{
+		IDSensor:  sensorID,
+		RawData:   buffer.RawData,
+		CreatedAt: timestamp, // Use the extracted timestamp for created_at
+		UpdatedAt: timestamp, // Use the same timestamp for updated_at
+	}
+}
 
-		buffer.LastRecordTime = time.Now()
-		// facades.Log().Info(fmt.Sprintf("Created record for sensor %s", sensorID))
+// sensorExists reports whether a sensor is registered. Lookups, missing
+// sensors included, are cached for cacheDuration so a message does not cost
+// a query.
+func (s *TCPSensorService) sensorExists(sensorID string) bool {
+	s.cacheMutex.Lock()
+	entry, exists := s.cache[sensorID]
+	s.cacheMutex.Unlock()
+	if exists && time.Since(entry.timestamp) < cacheDuration {
+		return entry.found
 	}
+
+	// Outside the cache mutex, so a slow query does not hold up other sensors
+	var sensor models.Sensor
+	// We should apply the soft-delete filter here if the Sensor model uses soft deletes
+	err := facades.Orm().Query().Where("id = ?", sensorID).FirstOrFail(&sensor)
+
+	s.cacheMutex.Lock()
+	s.cache[sensorID] = &sensorCacheEntry{found: err == nil, timestamp: time.Now()}
+	s.cacheMutex.Unlock()
+	return err == nil
 }
 
 // getOrCreateBuffer gets or creates a buffer for a sensor
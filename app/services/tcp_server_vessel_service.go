@@ -1,12 +1,15 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"goravel/app/helpers/nmea"
 	"goravel/app/models"
+	"io"
 	"net"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goravel/framework/facades"
@@ -14,9 +17,24 @@ import (
 
 // TCPVesselService handles TCP connections for vessel data
 type TCPVesselService struct {
-	listener net.Listener
-	mutex    sync.Mutex
-	cache    map[string]*CacheEntry
+	listener      net.Listener
+	connections   atomic.Int64
+	mutex         sync.Mutex
+	cacheMutex    sync.Mutex
+	cache         map[string]*CacheEntry
+	maxLineLength int
+	requireAuth   bool
+	ackMode       string
+	parser        *nmea.Parser
+	precedence    string
+	aisService    *AISService
+	archive       *NMEAArchive
+	sources       *SourceSelector
+	plausibility  *PositionFilter
+	deadReckoning deadReckoning
+	recording     trackRecording
+	writer        *RecordWriter
+	voyages       *VoyageTracker
 
 	// Vessel Process Service
 	bufferMutex   sync.Mutex
@@ -30,30 +48,30 @@ type CacheEntry struct {
 	timestamp time.Time
 }
 
-type NMEABuffer struct {
-	Latitude            string
-	Longitude           string
-	HeadingDegree       float64
-	SpeedInKnots        float64
-	GpsQualityIndicator models.GpsQuality
-	WaterDepth          float64
-	LastGGATime         time.Time
-	LastRecordTime      time.Time
-	LastVTGTime         time.Time // Track when we last got VTG data
-	LastHDTTime         time.Time // Track when we last got HDT data
-	mutex               sync.Mutex
-}
-
 const (
 	cacheDuration     = 1 * time.Minute
 	disconnectTimeout = 10 * time.Second // Lower timeout for quicker disconnect detection
 	checkInterval     = 5 * time.Second  // Check every 5 seconds
 )
 
-// NewTCPVesselService creates a new TCP vessel service
-func NewTCPVesselService() *TCPVesselService {
+// NewTCPVesselService creates a new TCP vessel service. A nil writer inserts
+// records directly.
+func NewTCPVesselService(aisService *AISService, archive *NMEAArchive, writer *RecordWriter) *TCPVesselService {
 	return &TCPVesselService{
+		aisService:    aisService,
+		archive:       archive,
 		cache:         make(map[string]*CacheEntry),
+		maxLineLength: facades.Config().GetInt("tcp.navigation.max_line_length", defaultMaxLineLength),
+		requireAuth:   facades.Config().GetBool("tcp.navigation.require_auth", false),
+		ackMode:       normalizeAckMode(facades.Config().GetString("tcp.navigation.ack_mode", AckModeEcho)),
+		parser:        newNMEAParser(),
+		precedence:    positionPrecedence(),
+		sources:       NewSourceSelector(),
+		plausibility:  NewPositionFilter(),
+		deadReckoning: newDeadReckoning(),
+		recording:     newTrackRecording(),
+		writer:        writer,
+		voyages:       NewVoyageTracker(writer),
 		nmeaBuffers:   make(map[string]*NMEABuffer),
 		activeVessels: make(map[string]*models.Kapal),
 	}
@@ -83,7 +101,7 @@ func (s *TCPVesselService) Start() error {
 	port := facades.Config().GetString("tcp.navigation.port", "8080")
 	address := fmt.Sprintf("%s:%s", host, port)
 
-	listener, err := net.Listen("tcp", address)
+	listener, err := listen("navigation", address)
 	if err != nil {
 		facades.Log().Error("❌ Failed to start TCP server", err)
 		return err
@@ -159,63 +177,135 @@ func (s *TCPVesselService) CheckDisconnectedVessels() {
 		for range ticker.C {
 			s.bufferMutex.Lock()
 			now := time.Now()
+			var disconnected []models.Kapal
 
 			// Only check vessels we know are active
 			for callSign, vessel := range s.activeVessels {
 				buffer, exists := s.nmeaBuffers[callSign]
 				if !exists {
 					facades.Log().Debug(fmt.Sprintf("Vessel %s marked as disconnected - Buffer not found", callSign))
-					s.updateLastRecordStatus(*vessel, "", models.Disconnected)
+					disconnected = append(disconnected, *vessel)
 					delete(s.activeVessels, callSign)
+					s.sources.Forget(callSign)
 					continue
 				}
 
 				// Check if vessel has exceeded timeout
-				if now.Sub(buffer.LastGGATime) > disconnectTimeout {
+				if now.Sub(buffer.LastPositionTime) > disconnectTimeout {
 					facades.Log().Debug(fmt.Sprintf("Vessel %s marked as disconnected - No data for %v",
 						callSign,
-						now.Sub(buffer.LastGGATime)))
-					s.updateLastRecordStatus(*vessel, "", models.Disconnected)
+						now.Sub(buffer.LastPositionTime)))
+					disconnected = append(disconnected, *vessel)
 					delete(s.nmeaBuffers, callSign) // Clean up the buffer
 					delete(s.activeVessels, callSign)
+					s.sources.Forget(callSign)
 				}
 			}
 
 			s.bufferMutex.Unlock()
+
+			s.markDisconnected(disconnected)
+			s.voyages.CloseStale(now)
 		}
 	}()
 }
 
-// handleConnection processes a single TCP connection
+// handleConnection reads newline framed data from a single TCP connection
 func (s *TCPVesselService) handleConnection(conn net.Conn) {
+	s.connections.Add(1)
 	defer func() {
+		s.connections.Add(-1)
 		conn.Close()
 		facades.Log().Info("👋 Connection closed: " + conn.RemoteAddr().String())
 	}()
 
-	buffer := make([]byte, 1024)
+	vc := newVesselConnection(conn, s.maxLineLength)
+
+	// With mutual TLS the certificate's common name is the vessel's call sign
+	commonName, err := peerCommonName(conn)
+	if err != nil {
+		facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: TLS handshake failed: %v", vc.remoteAddr(), err))
+		return
+	}
+	if commonName != "" {
+		if _, err := s.getKapal(commonName); err != nil {
+			facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: certificate CN %s is not a known call sign", vc.remoteAddr(), commonName))
+			return
+		}
+		facades.Log().Info(fmt.Sprintf("🔑 %s authenticated as %s by client certificate", vc.remoteAddr(), commonName))
+		vc.callSign = commonName
+		vc.certificateCN = commonName
+		vc.authenticated = true
+	}
+
 	for {
-		n, err := conn.Read(buffer)
+		line, err := vc.readLine()
+		if errors.Is(err, errLineTooLong) {
+			facades.Log().Warning(fmt.Sprintf("⚠️ Dropped line longer than %d bytes from %s", vc.maxLineLength, vc.remoteAddr()))
+			if writeErr := s.acknowledge(vc, "", rejectTooLong); writeErr != nil {
+				facades.Log().Error("📤 Write error", writeErr)
+				return
+			}
+			continue
+		}
+
+		if line != "" {
+			reason, keepOpen := s.handleLine(vc, line)
+			if writeErr := s.acknowledge(vc, line, reason); writeErr != nil {
+				facades.Log().Error("📤 Write error", writeErr)
+				return
+			}
+			if !keepOpen {
+				return
+			}
+		}
+
 		if err != nil {
-			if err.Error() != "EOF" {
+			if !errors.Is(err, io.EOF) {
 				facades.Log().Error("💥 Read error", err)
 			}
 			return
 		}
+	}
+}
+
+// acknowledge answers a line according to the connection's ack mode. reason
+// is empty for an accepted line. In echo mode a dropped line gets no reply,
+// as there is nothing left to echo.
+func (s *TCPVesselService) acknowledge(vc *vesselConnection, line string, reason string) error {
+	var reply string
+	switch s.ackModeFor(vc) {
+	case AckModeEcho:
+		if line == "" {
+			return nil
+		}
+		reply = line
+	case AckModeAck:
+		reply = formatAck(vc.sequence, reason)
+	default:
+		return nil
+	}
 
-		data := string(buffer[:n])
-		s.handleTCPData(data)
+	_, err := vc.conn.Write([]byte(reply + "\n"))
+	return err
+}
 
-		_, err = conn.Write(buffer[:n])
-		if err != nil {
-			facades.Log().Error("📤 Write error", err)
-			return
+// ackModeFor returns the identified vessel's ack mode, or the listener
+// default while the vessel is unknown or has none set
+func (s *TCPVesselService) ackModeFor(vc *vesselConnection) string {
+	if vc.callSign != "" {
+		if kapal, err := s.getKapal(vc.callSign); err == nil && ValidAckMode(kapal.AckMode) {
+			return kapal.AckMode
 		}
 	}
+	return s.ackMode
 }
 
 // getKapal retrieves a vessel by call sign, with caching
 func (s *TCPVesselService) getKapal(callSign string) (*models.Kapal, error) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
 	if entry, exists := s.cache[callSign]; exists {
 		if time.Since(entry.timestamp) < cacheDuration {
 			return entry.kapal, entry.error
@@ -231,6 +321,8 @@ func (s *TCPVesselService) getKapal(callSign string) (*models.Kapal, error) {
 		FirstOrFail(&kapal)
 
 	if err != nil {
+		// Counted once per database miss, cache hits for the same call sign are not
+		countUnknownCallSign(err)
 		// Cache the negative result too, to avoid repeated database queries
 		s.cache[callSign] = &CacheEntry{
 			kapal:     nil,
@@ -250,231 +342,305 @@ func (s *TCPVesselService) getKapal(callSign string) (*models.Kapal, error) {
 	return kapalPtr, nil
 }
 
-// handleTCPData processes incoming TCP data
-func (s *TCPVesselService) handleTCPData(data string) {
-	parts := strings.Split(data, ",")
-	if len(parts) < 2 {
-		facades.Log().Error("❌ Invalid data format")
-		return
+// ForgetKapal drops the cached vessel of a call sign, so the next lookup
+// reads its current row
+func (s *TCPVesselService) ForgetKapal(callSign string) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	delete(s.cache, callSign)
+}
+
+// handleLine processes one line from a connection. It returns the reject
+// reason, empty when the line was accepted, and whether the connection may
+// stay open. A line is either a sentence, a sentence behind a
+// CALLSIGN, prefix, or a CALLSIGN,TOKEN handshake. The call sign sticks to
+// the connection, so senders may identify once or prefix every packet.
+func (s *TCPVesselService) handleLine(vc *vesselConnection, line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", true
 	}
 
-	id, lines := strings.TrimSpace(parts[0]), strings.Split(strings.TrimSpace(strings.Join(parts[1:], ",")), "\n")
-	kapal, err := s.getKapal(id)
+	if line[0] != '$' && line[0] != '!' {
+		callSign, rest, _ := strings.Cut(line, ",")
+		callSign, rest = strings.TrimSpace(callSign), strings.TrimSpace(rest)
 
-	for _, line := range lines {
-		if err != nil {
-			if err.Error() == "record not found" {
-				facades.Log().Error("❌ Data not found for call sign: " + id)
-				return
-			}
-			facades.Log().Error("❌ Database error", err)
-			return
+		if rest != "" && rest[0] != '$' && rest[0] != '!' {
+			reason := s.authenticate(vc, callSign, rest)
+			return reason, reason == ""
+		}
+		if reason := s.identify(vc, callSign); reason != "" {
+			return reason, !s.requireAuth
 		}
+		line = rest
+		if line == "" {
+			return "", true // Handshake without a token
+		}
+	}
 
-		// Track this vessel as active
-		s.trackVessel(kapal)
+	if vc.callSign == "" {
+		vc.linesRejected++
+		if s.requireAuth {
+			facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: data before the handshake", vc.remoteAddr()))
+			return rejectAuth, false
+		}
+		if !vc.warnedNoIdent {
+			facades.Log().Warning(fmt.Sprintf("⚠️ Data from %s before a call sign was given, dropping", vc.remoteAddr()))
+			vc.warnedNoIdent = true
+		}
+		return rejectNoCallSign, true
+	}
+
+	kapal, err := s.getKapal(vc.callSign)
+	if err != nil {
+		vc.linesRejected++
+		return lookupRejectReason(err), true
+	}
+
+	// Track this vessel as active
+	s.trackVessel(kapal)
+	now := time.Now()
+	s.archive.Record(vc.callSign, vc.remoteAddr(), line, now)
 
-		s.processVesselData(*kapal, line)
+	reason := s.processVesselData(*kapal, SourceTCP, line, now)
+	if reason != "" {
+		vc.linesRejected++
 	}
+	return reason, true
 }
 
-// processVesselData handles different types of NMEA data
-func (s *TCPVesselService) processVesselData(kapal models.Kapal, data string) {
-	if len(data) >= 6 {
-		sentenceType := data[3:6]
-		switch sentenceType {
-		case "GGA":
-			s.processGGAData(kapal, data)
-		case "HDT":
-			s.processHDTData(kapal, data)
-		case "VTG":
-			s.processVTGData(kapal, data)
-		case "DBT", "DPT":
-			s.processDepthData(kapal, data)
-		}
+// lookupRejectReason tells a call sign that does not exist apart from a
+// database failure, which is worth retrying
+func lookupRejectReason(err error) string {
+	if err.Error() == "record not found" {
+		return rejectUnknownCallSign
 	}
+	return rejectServerError
 }
 
-// processGGAData handles GGA NMEA sentences (position data)
-func (s *TCPVesselService) processGGAData(kapal models.Kapal, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 15 {
-		facades.Log().Debug(fmt.Sprintf("Invalid GGA sentence, padding with defaults: %s", data))
-		fields = append(fields, make([]string, 15-len(fields))...) // Pad with empty strings
+// authenticate checks a CALLSIGN,TOKEN handshake against the vessel's device
+// secret and the tokens of its IPKapal entries. It returns the reject reason,
+// empty on success.
+func (s *TCPVesselService) authenticate(vc *vesselConnection, callSign string, token string) string {
+	if vc.certificateCN != "" && callSign != vc.certificateCN {
+		vc.linesRejected++
+		facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: certificate is for %s, handshake for %s", vc.remoteAddr(), vc.certificateCN, callSign))
+		return rejectAuth
 	}
 
-	buffer := s.getOrCreateBuffer(kapal.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
+	kapal, err := s.getKapal(callSign)
+	if err != nil {
+		vc.linesRejected++
+		facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: unknown call sign %s", vc.remoteAddr(), callSign))
+		return lookupRejectReason(err)
+	}
 
-	// Default values
-	latitude := "0.0"
-	longitude := "0.0"
+	if !verifyVesselToken(kapal, token) {
+		vc.linesRejected++
+		facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: invalid token for %s", vc.remoteAddr(), callSign))
+		return rejectAuth
+	}
 
-	// Parse latitude if available
-	if fields[2] != "" && fields[3] != "" {
-		latDMS, err := strconv.ParseFloat(fields[2], 64)
-		if err == nil {
-			latitude = formatCoordinate(latDMS, fields[3])
+	facades.Log().Info(fmt.Sprintf("🔑 %s authenticated as %s", vc.remoteAddr(), callSign))
+	vc.callSign = callSign
+	vc.authenticated = true
+	vc.warnedNoIdent = false
+	return ""
+}
+
+// identify sets the call sign of a connection from a CALLSIGN, prefix. Once
+// a connection is authenticated, or when authentication is required, only the
+// authenticated call sign is accepted. It returns the reject reason, empty
+// on success.
+func (s *TCPVesselService) identify(vc *vesselConnection, callSign string) string {
+	if callSign == vc.callSign {
+		return ""
+	}
+
+	if s.requireAuth || vc.authenticated {
+		vc.linesRejected++
+		if vc.authenticated {
+			facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: authenticated as %s but sent data for %s", vc.remoteAddr(), vc.callSign, callSign))
 		} else {
-			facades.Log().Debug(fmt.Sprintf("Error parsing latitude, using default 0: %v", err))
+			facades.Log().Warning(fmt.Sprintf("🔒 Rejected %s: no handshake for %s", vc.remoteAddr(), callSign))
 		}
+		return rejectAuth
 	}
 
-	// Parse longitude if available
-	if fields[4] != "" && fields[5] != "" {
-		longDMS, err := strconv.ParseFloat(fields[4], 64)
-		if err == nil {
-			longitude = formatCoordinate(longDMS, fields[5])
+	if _, err := s.getKapal(callSign); err != nil {
+		vc.callSign = ""
+		vc.linesRejected++
+		if err.Error() == "record not found" {
+			facades.Log().Error("❌ Data not found for call sign: " + callSign)
 		} else {
-			facades.Log().Debug(fmt.Sprintf("Error parsing longitude, using default 0: %v", err))
+			facades.Log().Error("❌ Database error", err)
 		}
+		return lookupRejectReason(err)
 	}
 
-	// Default GPS quality to 0 if field is empty
-	var gpsQuality models.GpsQuality
-	if fields[6] != "" {
-		gpsQuality = getGpsQualityFromIndicator(fields[6])
+	if vc.callSign == "" {
+		facades.Log().Info(fmt.Sprintf("🔗 %s identified as %s", vc.remoteAddr(), callSign))
 	}
+	vc.callSign = callSign
+	vc.authenticated = false
+	vc.warnedNoIdent = false
+	return ""
+}
 
-	// Update buffer with new GGA data
-	buffer.Latitude = latitude
-	buffer.Longitude = longitude
-	buffer.GpsQualityIndicator = gpsQuality
-	buffer.LastGGATime = time.Now()
+// processVesselData parses a single NMEA sentence from the named source and
+// applies it to the vessel's buffer. It returns the reject reason for a
+// malformed sentence; sentence types that are simply not used count as
+// accepted.
+func (s *TCPVesselService) processVesselData(kapal models.Kapal, source string, data string, now time.Time) string {
+	sentence, err := parseSentence(s.parser, kapal.CallSign, data)
+	if err != nil {
+		if errors.Is(err, nmea.ErrUnsupportedType) || errors.Is(err, nmea.ErrEmpty) {
+			return ""
+		}
+		facades.Log().Warning(fmt.Sprintf("Rejected sentence from %s: %v", kapal.CallSign, err))
+		if isChecksumError(err) {
+			return rejectChecksum
+		}
+		return rejectInvalid
+	}
+
+	// AIS traffic relayed by the vessel is tracked separately from its own navigation data
+	if vdm, isAIS := sentence.(nmea.VDM); isAIS {
+		s.aisService.HandleSentence(kapal.CallSign, kapal.CallSign, vdm)
+		return ""
+	}
+
+	s.processSentence(kapal, source, sentence, now)
+	return ""
+}
+
+// processSentence applies a decoded sentence to the vessel's buffer and
+// creates a record when the position changed and the vessel is due one.
+// Sentences from a source that is not selected for their role and
+// implausible positions are dropped. While the position is stale, heading
+// and speed sentences move a dead reckoning estimate instead.
+func (s *TCPVesselService) processSentence(kapal models.Kapal, source string, sentence nmea.Sentence, now time.Time) {
+	role := sentenceRole(sentence)
+	if role != "" && !s.sources.Accept(kapal.CallSign, kapal.SourcePriority, role, source, now) {
+		return
+	}
+	if fix, isPosition := positionFixOf(sentence); isPosition && s.plausibility.Check(kapal, source, fix, now) != "" {
+		return
+	}
+
+	buffer := s.getOrCreateBuffer(kapal.CallSign, now)
+	if record := s.updateBuffer(kapal, buffer, sentence, role, source, now); record != nil {
+		// Outside the buffer mutex, the voyage tracker and a full write queue
+		// wait on the database
+		s.storeVesselRecord(record)
+	}
+}
+
+// pendingVesselRecord is a record taken from a vessel's buffer, stored once
+// the buffer mutex is released
+type pendingVesselRecord struct {
+	record    models.VesselRecord
+	telemetry []models.VesselTelemetryRecord
+	latitude  float64 // Decimal degrees, for the voyage tracker
+	longitude float64
+}
+
+// updateBuffer applies a sentence to the buffer of a vessel and returns the
+// record that became due, nil when none did
+func (s *TCPVesselService) updateBuffer(kapal models.Kapal, buffer *NMEABuffer, sentence nmea.Sentence, role string, source string, now time.Time) *pendingVesselRecord {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	if buffer.applySentence(sentence, float64(kapal.Calibration), s.precedence, now) {
+		buffer.PositionSource = source
+	} else if !s.deadReckoning.project(buffer, role, now) || !s.deadReckoning.record {
+		return nil
+	}
 
 	// Check if we should create a record
-	if kapal.RecordStatus && time.Since(buffer.LastRecordTime) >= time.Duration(kapal.HistoryPerSecond)*time.Second {
-		timeSinceVTG := time.Since(buffer.LastVTGTime)
+	if kapal.RecordStatus && s.recording.due(kapal, buffer, now) {
+		timeSinceVTG := now.Sub(buffer.LastVTGTime)
 		facades.Log().Debug(fmt.Sprintf(
 			"Creating record for %s - Current Speed: %.2f knots, Last VTG update: %v ago",
 			kapal.CallSign,
 			buffer.SpeedInKnots,
 			timeSinceVTG,
 		))
-		s.createVesselRecord(kapal.CallSign, buffer, kapal.HistoryPerSecond)
-		buffer.LastRecordTime = time.Now()
+		record := s.createVesselRecord(kapal.CallSign, buffer, kapal.HistoryPerSecond, now)
+		s.recording.recorded(buffer, now)
+		return record
 	}
+	return nil
 }
 
-// createVesselRecord stores vessel data in the database
-// createVesselRecord stores vessel data in the database
-func (s *TCPVesselService) createVesselRecord(callSign string, buffer *NMEABuffer, historyPerSecond int64) {
+// createVesselRecord takes a vessel record and the environment readings that
+// changed from the buffer. The caller holds the buffer mutex.
+func (s *TCPVesselService) createVesselRecord(callSign string, buffer *NMEABuffer, historyPerSecond int64, now time.Time) *pendingVesselRecord {
 	// Only create record if we have the minimum required data
 	if buffer.Latitude == "" || buffer.Longitude == "" {
 		facades.Log().Debug(fmt.Sprintf("Skipping record creation for %s - missing position data", callSign))
-		return
+		return nil
 	}
 
-	var lastRecord models.VesselRecord
-	err := facades.Orm().Query().
-		Where("call_sign = ?", callSign).
-		Where("id = (SELECT MAX(id) FROM vessel_records WHERE call_sign = ?)", callSign).
-		First(&lastRecord)
-
-	newRecord := &models.VesselRecord{
+	receivedAt := buffer.LastPositionTime
+	newRecord := models.VesselRecord{
 		CallSign:            callSign,
 		Latitude:            buffer.Latitude,
 		Longitude:           buffer.Longitude,
 		HeadingDegree:       buffer.HeadingDegree,
+		RawHeadingDegree:    buffer.RawHeadingDegree,
+		HeadingSource:       buffer.HeadingSource,
 		SpeedInKnots:        buffer.SpeedInKnots,
 		GpsQualityIndicator: buffer.GpsQualityIndicator,
 		WaterDepth:          buffer.WaterDepth,
 		TelnetStatus:        models.Connected,
+		ReceivedAt:          &receivedAt,
+		Source:              buffer.PositionSource,
+		CreatedAt:           now, // Set here so a queued record keeps the time it was taken
+		UpdatedAt:           now,
 	}
 
-	if err == nil {
-		newRecord.SeriesID = lastRecord.SeriesID + 1
-	} else {
-		newRecord.SeriesID = 1
+	// Fall back to the arrival time when the position sentence carried no UTC time
+	if !buffer.PositionFixTime.IsZero() {
+		fixTime := buffer.PositionFixTime
+		newRecord.FixTime = &fixTime
 	}
 
-	// Create new record
-	err = facades.Orm().Query().Create(newRecord)
-	if err != nil {
-		facades.Log().Error(fmt.Sprintf("Failed to create vessel record: %v", err))
-	} else {
-		facades.Log().Debug(fmt.Sprintf("Successfully created vessel record - CallSign: %s, Speed: %.2f, SeriesID: %d",
-			callSign, newRecord.SpeedInKnots, newRecord.SeriesID))
+	latitude, longitude := buffer.position()
+	return &pendingVesselRecord{
+		record:    newRecord,
+		telemetry: telemetryRecords(callSign, buffer, now),
+		latitude:  latitude,
+		longitude: longitude,
 	}
 }
 
-// processHDTData handles HDT NMEA sentences (heading data)
-func (s *TCPVesselService) processHDTData(kapal models.Kapal, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 3 {
-		facades.Log().Debug(fmt.Sprintf("Invalid HDT sentence, using default values: %s", data))
-		fields = append(fields, make([]string, 3-len(fields))...) // Pad with empty strings
-	}
+// storeVesselRecord links a record to its voyage and queues it with its
+// environment readings
+func (s *TCPVesselService) storeVesselRecord(pending *pendingVesselRecord) {
+	record := pending.record
+	record.VoyageID = s.voyages.Observe(record.CallSign, pending.latitude, pending.longitude, record.SpeedInKnots, record.CreatedAt)
 
-	buffer := s.getOrCreateBuffer(kapal.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	// Default to 0 if field is empty or invalid
-	heading := 0.0
-	if fields[1] != "" {
-		parsedHeading, err := strconv.ParseFloat(fields[1], 64)
-		if err != nil {
-			facades.Log().Debug(fmt.Sprintf("Error parsing heading, using default 0: %v", err))
-		} else {
-			heading = parsedHeading
-		}
-	}
-
-	buffer.HeadingDegree = heading
-	buffer.LastHDTTime = time.Now()
-}
+	s.writer.WriteVesselRecord(record)
+	facades.Log().Debug(fmt.Sprintf("Queued vessel record - CallSign: %s, Speed: %.2f", record.CallSign, record.SpeedInKnots))
 
-// processVTGData handles VTG NMEA sentences (speed data)
-func (s *TCPVesselService) processVTGData(kapal models.Kapal, data string) {
-	fields := strings.Split(strings.TrimSpace(data), ",")
-	facades.Log().Debug(fmt.Sprintf("Processing VTG data for %s: %v", kapal.CallSign, fields))
-
-	if len(fields) < 10 {
-		facades.Log().Debug(fmt.Sprintf("Invalid VTG sentence, padding with defaults: %s", data))
-		fields = append(fields, make([]string, 10-len(fields))...) // Pad with empty strings
-	}
-
-	buffer := s.getOrCreateBuffer(kapal.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	// Default to 0 if speed field is empty or invalid
-	speedKnots := 0.0
-	if fields[5] != "" {
-		parsedSpeed, err := strconv.ParseFloat(strings.TrimSpace(fields[5]), 64)
-		if err != nil {
-			facades.Log().Debug(fmt.Sprintf("Error parsing speed, using default 0: %v", err))
-		} else {
-			speedKnots = parsedSpeed
-		}
+	if len(pending.telemetry) > 0 {
+		s.writer.WriteTelemetryRecords(pending.telemetry)
 	}
-
-	buffer.SpeedInKnots = speedKnots
-	buffer.LastVTGTime = time.Now()
-	facades.Log().Debug(fmt.Sprintf("Updated VTG speed for %s: %.2f knots", kapal.CallSign, speedKnots))
 }
 
-// processDepthData handles depth NMEA sentences (DBT/DPT)
-func (s *TCPVesselService) processDepthData(kapal models.Kapal, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 3 {
-		facades.Log().Error("Invalid depth sentence")
+// markDisconnected sets the last record of each vessel to disconnected. It
+// queries the database, so callers must not hold the buffer mutex.
+func (s *TCPVesselService) markDisconnected(vessels []models.Kapal) {
+	if len(vessels) == 0 {
 		return
 	}
 
-	buffer := s.getOrCreateBuffer(kapal.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	depth, err := strconv.ParseFloat(fields[1], 64)
-	if err != nil {
-		facades.Log().Error("Error parsing depth:", err)
-		return
+	// Write the queued records first, so the status lands on the newest one
+	s.writer.Flush()
+	for _, vessel := range vessels {
+		s.updateLastRecordStatus(vessel, "", models.Disconnected)
 	}
-
-	buffer.WaterDepth = depth
 }
 
 // updateLastRecordStatus updates the status in the last vessel record
@@ -503,7 +669,7 @@ func (s *TCPVesselService) updateLastRecordStatus(kapal models.Kapal, data strin
 }
 
 // getOrCreateBuffer gets or creates a NMEA buffer for a vessel
-func (s *TCPVesselService) getOrCreateBuffer(callSign string) *NMEABuffer {
+func (s *TCPVesselService) getOrCreateBuffer(callSign string, now time.Time) *NMEABuffer {
 	s.bufferMutex.Lock()
 	defer s.bufferMutex.Unlock()
 
@@ -511,15 +677,15 @@ func (s *TCPVesselService) getOrCreateBuffer(callSign string) *NMEABuffer {
 		return buffer
 	}
 
-	now := time.Now()
 	buffer := &NMEABuffer{
-		LastGGATime:    now,
-		LastRecordTime: now,
-		LastVTGTime:    now,
-		LastHDTTime:    now,
-		SpeedInKnots:   0,
-		HeadingDegree:  0,
-		WaterDepth:     0,
+		LastGGATime:      now,
+		LastPositionTime: now,
+		LastRecordTime:   now,
+		LastVTGTime:      now,
+		LastHDTTime:      now,
+		SpeedInKnots:     0,
+		HeadingDegree:    0,
+		WaterDepth:       0,
 	}
 
 	s.nmeaBuffers[callSign] = buffer
@@ -529,16 +695,21 @@ func (s *TCPVesselService) getOrCreateBuffer(callSign string) *NMEABuffer {
 // Stop gracefully shuts down the TCP server
 func (s *TCPVesselService) Stop() error {
 	s.bufferMutex.Lock()
-	defer s.bufferMutex.Unlock()
-
-	// Mark all active vessels as disconnected before stopping
+	disconnected := make([]models.Kapal, 0, len(s.activeVessels))
 	for _, vessel := range s.activeVessels {
-		s.updateLastRecordStatus(*vessel, "", models.Disconnected)
+		disconnected = append(disconnected, *vessel)
 	}
 
 	// Clear maps
 	s.activeVessels = make(map[string]*models.Kapal)
 	s.nmeaBuffers = make(map[string]*NMEABuffer)
+	s.bufferMutex.Unlock()
+
+	// Keep the statistics of voyages underway for the next start
+	s.voyages.Save()
+
+	// Mark all active vessels as disconnected before stopping
+	s.markDisconnected(disconnected)
 
 	if s.listener != nil {
 		return s.listener.Close()
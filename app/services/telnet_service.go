@@ -3,10 +3,12 @@ package services
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"goravel/app/helpers/nmea"
 	"goravel/app/models"
 	"net"
-	"strconv"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -19,43 +21,93 @@ type TelnetService struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	sessions    map[uint]*TelnetConnection
-	nmeaBuffers map[string]*NMEABuffer // key is CallSign
+	vessels     *TCPVesselService // Shared pipeline, so telnet and pushed data fail over instead of duplicating records
+	parser      *nmea.Parser
+	aisService  *AISService
+	archive     *NMEAArchive
+	maxFailures int
 	mu          sync.RWMutex
-	bufferMutex sync.RWMutex
 	isRunning   bool
+
+	soundingMutex      sync.Mutex
+	soundingInterval   time.Duration
+	latestSoundings    map[uint]*SoundingData // key is session ID
+	lastSoundingStored map[uint]time.Time
 }
 
 type TelnetConnection struct {
 	Session *models.TelnetSession
-	Conn    net.Conn
+	Conn    net.Conn // Nil while connecting or backing off
 	Context context.Context
 	Cancel  context.CancelFunc
+
+	stateMutex sync.Mutex
+	state      TelnetSessionState
 }
 
-// NewTelnetService creates a new telnet service instance
-func NewTelnetService() *TelnetService {
-	ctx, cancel := context.WithCancel(context.Background())
-	return &TelnetService{
-		ctx:         ctx,
-		cancel:      cancel,
-		sessions:    make(map[uint]*TelnetConnection),
-		nmeaBuffers: make(map[string]*NMEABuffer),
-	}
+// States of a telnet session
+const (
+	TelnetStateConnecting = "connecting"
+	TelnetStateConnected  = "connected"
+	TelnetStateBackoff    = "backoff"
+	TelnetStateDisabled   = "disabled" // Soft-deleted, or gave up after max_failures until the session is edited
+)
+
+const (
+	telnetMinBackoff = 5 * time.Second
+	telnetMaxBackoff = 5 * time.Minute
+)
+
+// TelnetSessionState reports the health of one telnet session
+type TelnetSessionState struct {
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	CallSign      *string    `json:"call_sign"`
+	Address       string     `json:"address"`
+	State         string     `json:"state"`
+	Failures      int        `json:"failures"` // Consecutive failed attempts
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at"`
+	ConnectedAt   *time.Time `json:"connected_at"`
+	LastDataAt    *time.Time `json:"last_data_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	BytesReceived uint64     `json:"bytes_received"`
+	LinesReceived uint64     `json:"lines_received"`
+	Reconnects    uint64     `json:"reconnects"`
 }
 
-// GetBufferMutex returns the buffer mutex
-func (ts *TelnetService) GetBufferMutex() *sync.RWMutex {
-	return &ts.bufferMutex
+// updateState changes the session state under its lock
+func (tc *TelnetConnection) updateState(update func(*TelnetSessionState)) {
+	tc.stateMutex.Lock()
+	defer tc.stateMutex.Unlock()
+	update(&tc.state)
 }
 
-// GetNMEABuffers returns the NMEA buffers map
-func (ts *TelnetService) GetNMEABuffers() map[string]*NMEABuffer {
-	return ts.nmeaBuffers
+// State returns a copy of the session state
+func (tc *TelnetConnection) State() TelnetSessionState {
+	tc.stateMutex.Lock()
+	defer tc.stateMutex.Unlock()
+	return tc.state
 }
 
-// Add this method to NMEABuffer
-func (b *NMEABuffer) GetMutex() *sync.Mutex {
-	return &b.mutex
+// NewTelnetService creates a new telnet service instance that feeds NMEA
+// sessions into the buffers of vessels
+func NewTelnetService(aisService *AISService, archive *NMEAArchive, vessels *TCPVesselService) *TelnetService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TelnetService{
+		ctx:         ctx,
+		cancel:      cancel,
+		sessions:    make(map[uint]*TelnetConnection),
+		vessels:     vessels,
+		parser:      newNMEAParser(),
+		aisService:  aisService,
+		archive:     archive,
+		maxFailures: facades.Config().GetInt("tcp.telnet.max_failures", 0),
+
+		soundingInterval:   time.Duration(facades.Config().GetInt("tcp.telnet.sounding_interval", 1)) * time.Second,
+		latestSoundings:    make(map[uint]*SoundingData),
+		lastSoundingStored: make(map[uint]time.Time),
+	}
 }
 
 // Start initializes and runs the telnet service
@@ -71,9 +123,6 @@ func (ts *TelnetService) Start() error {
 	// Start monitoring for sessions in background
 	go ts.monitorSessions()
 
-	// Start buffer cleanup routine
-	go ts.cleanupOldBuffers()
-
 	return nil
 }
 
@@ -109,18 +158,30 @@ func (ts *TelnetService) updateSessions() {
 
 	currentSessions := make(map[uint]bool)
 
-	for _, session := range sessions {
+	for i := range sessions {
+		session := &sessions[i]
 		currentSessions[session.ID] = true
 
+		if session.DeletedAt != nil {
+			if conn, exists := ts.sessions[session.ID]; !exists || conn.State().State != TelnetStateDisabled {
+				ts.stopSession(session.ID)
+				ts.disableSession(session, "session is deleted")
+			}
+			continue
+		}
+
 		if conn, exists := ts.sessions[session.ID]; exists {
-			// Check if connection details changed
-			if conn.Session.IP != session.IP || conn.Session.Port != session.Port {
+			// Restart when the connection details changed, or when a session
+			// that was given up on has been edited since
+			changed := conn.Session.IP != session.IP || conn.Session.Port != session.Port
+			edited := conn.State().State == TelnetStateDisabled && !conn.Session.UpdatedAt.Equal(session.UpdatedAt)
+			if changed || edited {
 				ts.stopSession(session.ID)
-				ts.startSession(&session)
+				ts.startSession(session)
 			}
 		} else {
 			// Start new session
-			ts.startSession(&session)
+			ts.startSession(session)
 		}
 	}
 
@@ -132,31 +193,133 @@ func (ts *TelnetService) updateSessions() {
 	}
 }
 
-// startSession initiates a new telnet connection
-func (ts *TelnetService) startSession(session *models.TelnetSession) {
-	ctx, cancel := context.WithCancel(ts.ctx)
-
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", session.IP, session.Port), 10*time.Second)
-	if err != nil {
-		facades.Log().Error(fmt.Sprintf("Failed to connect to %s:%d: %v", session.IP, session.Port, err))
-		cancel()
-		return
-	}
-
-	tc := &TelnetConnection{
+// newTelnetConnection creates the tracked connection of a session
+func newTelnetConnection(session *models.TelnetSession, ctx context.Context, cancel context.CancelFunc) *TelnetConnection {
+	return &TelnetConnection{
 		Session: session,
-		Conn:    conn,
 		Context: ctx,
 		Cancel:  cancel,
+		state: TelnetSessionState{
+			ID:       session.ID,
+			Name:     session.Name,
+			CallSign: session.CallSign,
+			Address:  fmt.Sprintf("%s:%d", session.IP, session.Port),
+			State:    TelnetStateConnecting,
+		},
 	}
+}
 
+// startSession runs the connection loop of a session. The caller holds ts.mu.
+func (ts *TelnetService) startSession(session *models.TelnetSession) {
+	ctx, cancel := context.WithCancel(ts.ctx)
+	tc := newTelnetConnection(session, ctx, cancel)
 	ts.sessions[session.ID] = tc
 
-	go ts.readTelnetData(tc)
+	go ts.runSession(tc)
 }
 
-// readTelnetData reads data from telnet connection
-func (ts *TelnetService) readTelnetData(tc *TelnetConnection) {
+// disableSession tracks a session that is not dialled. The caller holds ts.mu.
+func (ts *TelnetService) disableSession(session *models.TelnetSession, reason string) {
+	ctx, cancel := context.WithCancel(ts.ctx)
+	cancel()
+	tc := newTelnetConnection(session, ctx, cancel)
+	tc.state.State = TelnetStateDisabled
+	tc.state.LastError = reason
+	ts.sessions[session.ID] = tc
+}
+
+// runSession keeps a session connected: connecting, then connected until the
+// connection drops, then backoff with an exponentially growing delay. After
+// max_failures consecutive failed dials the session is disabled until it is
+// edited.
+func (ts *TelnetService) runSession(tc *TelnetConnection) {
+	for {
+		tc.updateState(func(state *TelnetSessionState) {
+			state.State = TelnetStateConnecting
+			state.NextAttemptAt = nil
+		})
+
+		dialer := net.Dialer{Timeout: 10 * time.Second}
+		conn, err := dialer.DialContext(tc.Context, "tcp", fmt.Sprintf("%s:%d", tc.Session.IP, tc.Session.Port))
+		if tc.Context.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			now := time.Now()
+			tc.updateState(func(state *TelnetSessionState) {
+				state.State = TelnetStateConnected
+				state.ConnectedAt = &now
+				state.Failures = 0
+			})
+			ts.mu.Lock()
+			tc.Conn = conn
+			ts.mu.Unlock()
+
+			err = ts.readTelnetData(tc, conn)
+
+			ts.mu.Lock()
+			tc.Conn = nil
+			ts.mu.Unlock()
+			if tc.Context.Err() != nil {
+				return
+			}
+			tc.updateState(func(state *TelnetSessionState) { state.Reconnects++ })
+		} else {
+			facades.Log().Error(fmt.Sprintf("Failed to connect to %s:%d: %v", tc.Session.IP, tc.Session.Port, err))
+		}
+
+		now := time.Now()
+		var failures int
+		tc.updateState(func(state *TelnetSessionState) {
+			if err != nil {
+				state.LastError = err.Error()
+				state.LastErrorAt = &now
+			}
+			state.Failures++
+			failures = state.Failures
+		})
+
+		if ts.maxFailures > 0 && failures >= ts.maxFailures {
+			facades.Log().Warning(fmt.Sprintf("Disabled telnet session %s after %d failures", tc.Session.Name, failures))
+			tc.updateState(func(state *TelnetSessionState) { state.State = TelnetStateDisabled })
+			return
+		}
+
+		delay := telnetMinBackoff << min(failures-1, 6)
+		if delay > telnetMaxBackoff {
+			delay = telnetMaxBackoff
+		}
+		next := now.Add(delay)
+		tc.updateState(func(state *TelnetSessionState) {
+			state.State = TelnetStateBackoff
+			state.NextAttemptAt = &next
+		})
+
+		select {
+		case <-tc.Context.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// GetSessionStates returns the state of every known session, by ID
+func (ts *TelnetService) GetSessionStates() []TelnetSessionState {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	states := make([]TelnetSessionState, 0, len(ts.sessions))
+	for _, tc := range ts.sessions {
+		states = append(states, tc.State())
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+// readTelnetData reads lines from an established connection until it fails
+// or the session is stopped, and returns the read error
+func (ts *TelnetService) readTelnetData(tc *TelnetConnection, conn net.Conn) error {
 	defer func() {
 		fmt.Printf("Closing connection for %s (IP: %s:%d)\n", tc.Session.Name, tc.Session.IP, tc.Session.Port)
 
@@ -165,34 +328,43 @@ func (ts *TelnetService) readTelnetData(tc *TelnetConnection) {
 			ts.updateLastRecordStatus(*tc.Session.CallSign, models.Disconnected)
 		}
 
-		tc.Conn.Close()
-		tc.Cancel()
-		ts.mu.Lock()
-		delete(ts.sessions, tc.Session.ID)
-		ts.mu.Unlock()
+		conn.Close()
 	}()
 
 	fmt.Printf("Started reading data from %s (IP: %s:%d)\n", tc.Session.Name, tc.Session.IP, tc.Session.Port)
 
-	reader := bufio.NewReader(tc.Conn)
+	reader := bufio.NewReader(conn)
+	var pending string // Start of a line cut off by the read deadline
 	for {
 		// Set a short read deadline to prevent blocking
-		tc.Conn.SetReadDeadline(time.Now().Add(1 * time.Second))
+		conn.SetReadDeadline(time.Now().Add(1 * time.Second))
 
 		select {
 		case <-tc.Context.Done():
-			return
+			return nil
 		default:
 			line, err := reader.ReadString('\n')
+			if len(line) > 0 {
+				now := time.Now()
+				tc.updateState(func(state *TelnetSessionState) {
+					state.BytesReceived += uint64(len(line))
+					state.LastDataAt = &now
+					if err == nil {
+						state.LinesReceived++
+					}
+				})
+			}
 			if err != nil {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					// Just a timeout, continue reading
+					// Just a timeout, keep the partial line and continue reading
+					pending += line
 					continue
 				}
 				fmt.Printf("Read error for %s: %v\n", tc.Session.Name, err)
-				return
+				return err
 			}
 
+			line, pending = pending+line, ""
 			if line = strings.TrimSpace(line); line != "" {
 				// fmt.Printf("📡 [%s] Raw data received: %s\n", tc.Session.Name, line)
 				ts.processData(tc.Session, line)
@@ -233,271 +405,68 @@ func (ts *TelnetService) processData(session *models.TelnetSession, data string)
 		RawData:  data,
 	}
 
-	// Handle NMEA data if applicable
-	if session.TypeIP != nil && ts.isNMEAData(data) {
-		ts.processNMEAData(session, data)
-	} else {
-		err := facades.Orm().Query().Create(record)
-		if err != nil {
-			facades.Log().Error(fmt.Sprintf("Failed to save telnet record: %v", err))
-			return
-		}
-	}
-}
-
-// isNMEAData checks if data is NMEA format
-func (ts *TelnetService) isNMEAData(data string) bool {
-	return len(data) > 0 && data[0] == '$' && len(data) > 6
-}
-
-// processNMEAData handles NMEA format data
-func (ts *TelnetService) processNMEAData(session *models.TelnetSession, data string) {
-	if session.TypeIP == nil {
-		return
+	if session.CallSign != nil {
+		ts.archive.Record(*session.CallSign, fmt.Sprintf("%s:%d", session.IP, session.Port), data, time.Now())
 	}
 
-	typeIP := *session.TypeIP
-	if typeIP == "all" {
-		// For "all" type, determine the sentence type and process accordingly
-		if len(data) >= 6 {
-			sentenceType := data[3:6]
-			switch sentenceType {
-			case "GGA":
-				ts.processGGAData(session, data)
-			case "HDT":
-				ts.processHDTData(session, data)
-			case "VTG":
-				ts.processVTGData(session, data)
-			case "DBT", "DPT":
-				ts.processDepthData(session, data)
-			}
-		}
-	} else if ts.shouldProcessNMEAType(data, typeIP) {
-		// Process specific type as before
-		switch typeIP {
-		case "gga":
-			ts.processGGAData(session, data)
-		case "hdt":
-			ts.processHDTData(session, data)
-		case "vtg":
-			ts.processVTGData(session, data)
-		case "depth":
-			ts.processDepthData(session, data)
+	// Fathometer depths become soundings, other output is kept as a raw record
+	if session.Type != nil && *session.Type == "fathometer" {
+		if ts.processSoundingData(session, data) {
+			return
 		}
-	}
-}
-
-func (ts *TelnetService) processGGAData(session *models.TelnetSession, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 15 {
-		facades.Log().Error("Invalid GGA sentence")
-		return
-	}
-
-	buffer := ts.getOrCreateBuffer(*session.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	// Parse and update GGA data as before
-	latDMS, err := strconv.ParseFloat(fields[2], 64)
-	if err != nil {
-		facades.Log().Error("Error parsing latitude:", err)
-		return
-	}
-	latDir := fields[3]
-
-	longDMS, err := strconv.ParseFloat(fields[4], 64)
-	if err != nil {
-		facades.Log().Error("Error parsing longitude:", err)
+	} else if session.TypeIP != nil && ts.isNMEAData(data) {
+		// Handle NMEA data if applicable
+		ts.processNMEAData(session, data)
 		return
 	}
-	longDir := fields[5]
-
-	latitude := formatCoordinate(latDMS, latDir)
-	longitude := formatCoordinate(longDMS, longDir)
-	gpsQuality := getGpsQualityFromIndicator(fields[6])
 
-	// Fetch Kapal data
-	var kapal models.Kapal
-	err = facades.Orm().Query().Where("call_sign = ?", *session.CallSign).First(&kapal)
+	err := facades.Orm().Query().Create(record)
 	if err != nil {
-		facades.Log().Error(fmt.Sprintf("Failed to fetch kapal data for %s: %v", *session.CallSign, err))
+		facades.Log().Error(fmt.Sprintf("Failed to save telnet record: %v", err))
 		return
 	}
-
-	// Update buffer with new GGA data
-	buffer.Latitude = latitude
-	buffer.Longitude = longitude
-	buffer.GpsQualityIndicator = gpsQuality
-	buffer.LastGGATime = time.Now()
-
-	// Check if we should create a record
-	if kapal.RecordStatus && time.Since(buffer.LastRecordTime) >= time.Duration(kapal.HistoryPerSecond)*time.Second {
-		timeSinceVTG := time.Since(buffer.LastVTGTime)
-
-		facades.Log().Debug(fmt.Sprintf(
-			"Creating record for %s - Current Speed: %.2f knots, Last VTG update: %v ago",
-			*session.CallSign,
-			buffer.SpeedInKnots,
-			timeSinceVTG,
-		))
-
-		ts.createVesselRecord(*session.CallSign, buffer, kapal.HistoryPerSecond)
-		buffer.LastRecordTime = time.Now()
-	}
 }
 
-func (ts *TelnetService) processHDTData(session *models.TelnetSession, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 3 {
-		facades.Log().Error("Invalid HDT sentence")
-		return
-	}
-
-	buffer := ts.getOrCreateBuffer(*session.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	heading, err := strconv.ParseFloat(fields[1], 64)
-	if err != nil {
-		facades.Log().Error("Error parsing heading:", err)
-		return
-	}
-
-	// Store the heading and update timestamp
-	buffer.HeadingDegree = heading
-	buffer.LastHDTTime = time.Now()
+// isNMEAData checks if data is NMEA format
+func (ts *TelnetService) isNMEAData(data string) bool {
+	return len(data) > 6 && (data[0] == '$' || data[0] == '!')
 }
 
-func (ts *TelnetService) processVTGData(session *models.TelnetSession, data string) {
-	fields := strings.Split(strings.TrimSpace(data), ",")
-
-	// Debug log the incoming VTG data
-	facades.Log().Debug(fmt.Sprintf("Processing VTG data for %s: %v", *session.CallSign, fields))
-
-	// VTG has 10 fields including the sentence ID
-	if len(fields) < 6 {
-		facades.Log().Error(fmt.Sprintf("Invalid VTG sentence (not enough fields): %s", data))
+// processNMEAData handles NMEA format data
+func (ts *TelnetService) processNMEAData(session *models.TelnetSession, data string) {
+	if session.TypeIP == nil || session.CallSign == nil {
 		return
 	}
 
-	// Speed is in field 5 (index 5)
-	speedField := strings.TrimSpace(fields[5])
-	if speedField == "" {
-		facades.Log().Debug(fmt.Sprintf("Empty speed field in VTG data for %s", *session.CallSign))
+	// Sessions configured for a single sentence family ignore everything else
+	typeIP := *session.TypeIP
+	if typeIP != "all" && !ts.shouldProcessNMEAType(data, typeIP) {
 		return
 	}
 
-	buffer := ts.getOrCreateBuffer(*session.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	speedKnots, err := strconv.ParseFloat(speedField, 64)
+	callSign := *session.CallSign
+	sentence, err := parseSentence(ts.parser, callSign, data)
 	if err != nil {
-		facades.Log().Error(fmt.Sprintf("Error parsing speed from VTG data: %v, Speed field: %s", err, speedField))
+		if !errors.Is(err, nmea.ErrUnsupportedType) && !errors.Is(err, nmea.ErrEmpty) {
+			facades.Log().Warning(fmt.Sprintf("Rejected sentence from %s: %v", session.Name, err))
+		}
 		return
 	}
 
-	// Update the buffer with the new speed
-	buffer.SpeedInKnots = speedKnots
-	buffer.LastVTGTime = time.Now()
-
-	facades.Log().Debug(fmt.Sprintf("Updated VTG speed for %s: %.2f knots", *session.CallSign, speedKnots))
-}
-
-func (ts *TelnetService) processDepthData(session *models.TelnetSession, data string) {
-	fields := strings.Split(data, ",")
-	if len(fields) < 3 {
-		facades.Log().Error("Invalid depth sentence")
+	// AIS traffic relayed by the vessel is tracked separately from its own navigation data
+	if vdm, isAIS := sentence.(nmea.VDM); isAIS {
+		ts.aisService.HandleSentence(fmt.Sprintf("telnet-%d", session.ID), callSign, vdm)
 		return
 	}
 
-	buffer := ts.getOrCreateBuffer(*session.CallSign)
-	buffer.mutex.Lock()
-	defer buffer.mutex.Unlock()
-
-	depth, err := strconv.ParseFloat(fields[1], 64)
+	kapal, err := ts.vessels.getKapal(callSign)
 	if err != nil {
-		facades.Log().Error("Error parsing depth:", err)
+		facades.Log().Error(fmt.Sprintf("Failed to fetch kapal data for %s: %v", callSign, err))
 		return
 	}
 
-	buffer.WaterDepth = depth
-}
-
-func (ts *TelnetService) getOrCreateBuffer(callSign string) *NMEABuffer {
-	ts.bufferMutex.Lock()
-	defer ts.bufferMutex.Unlock()
-
-	if buffer, exists := ts.nmeaBuffers[callSign]; exists {
-		return buffer
-	}
-
-	now := time.Now()
-	buffer := &NMEABuffer{
-		LastGGATime:    now,
-		LastRecordTime: now,
-		LastVTGTime:    now, // Initialize to current time instead of zero time
-		LastHDTTime:    now, // Initialize to current time
-		SpeedInKnots:   0,
-		HeadingDegree:  0,
-		WaterDepth:     0,
-	}
-
-	ts.nmeaBuffers[callSign] = buffer
-	return buffer
-}
-
-func (ts *TelnetService) createVesselRecord(callSign string, buffer *NMEABuffer, historyPerSecond int64) {
-	// Only create record if we have the minimum required data
-	if buffer.Latitude == "" || buffer.Longitude == "" {
-		facades.Log().Debug(fmt.Sprintf("Skipping record creation for %s - missing position data", callSign))
-		return
-	}
-
-	var lastRecord models.VesselRecord
-	err := facades.Orm().Query().Where("call_sign = ?", callSign).Order("created_at DESC").First(&lastRecord)
-
-	// Debug log all values before creating record
-	facades.Log().Debug(fmt.Sprintf(
-		"Creating vessel record for %s:\n"+
-			"Speed: %.2f knots\n"+
-			"Position: %s, %s\n"+
-			"Heading: %.2f\n"+
-			"Last VTG update: %v ago",
-		callSign,
-		buffer.SpeedInKnots,
-		buffer.Latitude,
-		buffer.Longitude,
-		buffer.HeadingDegree,
-		time.Since(buffer.LastVTGTime),
-	))
-
-	newRecord := &models.VesselRecord{
-		CallSign:            callSign,
-		Latitude:            buffer.Latitude,
-		Longitude:           buffer.Longitude,
-		HeadingDegree:       buffer.HeadingDegree,
-		SpeedInKnots:        buffer.SpeedInKnots,
-		GpsQualityIndicator: buffer.GpsQualityIndicator,
-		WaterDepth:          buffer.WaterDepth,
-		TelnetStatus:        models.Connected,
-	}
-
-	if err == nil {
-		newRecord.SeriesID = lastRecord.SeriesID + 1
-	} else {
-		newRecord.SeriesID = 1
-	}
-
-	// Create new record
-	err = facades.Orm().Query().Create(newRecord)
-	if err != nil {
-		facades.Log().Error(fmt.Sprintf("Failed to create vessel record: %v", err))
-	} else {
-		facades.Log().Debug(fmt.Sprintf("Successfully created vessel record - CallSign: %s, Speed: %.2f, SeriesID: %d",
-			callSign, newRecord.SpeedInKnots, newRecord.SeriesID))
-	}
+	ts.vessels.trackVessel(kapal)
+	ts.vessels.processSentence(*kapal, sourceName(SourceTelnet, session.ID), sentence, time.Now())
 }
 
 func (ts *TelnetService) updateVesselRecord(callSign string, updateFn func(*models.VesselRecord)) {
@@ -532,29 +501,8 @@ func (ts *TelnetService) updateVesselRecord(callSign string, updateFn func(*mode
 	}
 }
 
-func formatCoordinate(dms float64, direction string) string {
-	degrees := int(dms / 100)
-	minutes := dms - float64(degrees*100)
-	return fmt.Sprintf("%d°%.4f°%s", degrees, minutes, direction)
-}
-
 func (ts *TelnetService) shouldProcessNMEAType(data string, typeIP string) bool {
-	if len(data) < 6 {
-		return false
-	}
-
-	switch typeIP {
-	case "gga":
-		return data[3:6] == "GGA"
-	case "hdt":
-		return data[3:6] == "HDT"
-	case "vtg":
-		return data[3:6] == "VTG"
-	case "depth":
-		return data[3:6] == "DBT" || data[3:6] == "DPT"
-	default:
-		return false
-	}
+	return acceptsSentenceType(typeIP, nmea.SentenceType(data))
 }
 
 // Stop stops the telnet service
@@ -583,50 +531,10 @@ func (ts *TelnetService) stopSession(sessionID uint) {
 			ts.updateLastRecordStatus(*conn.Session.CallSign, models.Disconnected)
 		}
 		conn.Cancel()
-		conn.Conn.Close()
-		delete(ts.sessions, sessionID)
-	}
-}
-
-func (ts *TelnetService) cleanupOldBuffers() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ts.ctx.Done():
-			return
-		case <-ticker.C:
-			ts.bufferMutex.Lock()
-			for callSign, buffer := range ts.nmeaBuffers {
-				buffer.mutex.Lock()
-				if time.Since(buffer.LastGGATime) > 10*time.Minute {
-					delete(ts.nmeaBuffers, callSign)
-				}
-				buffer.mutex.Unlock()
-			}
-			ts.bufferMutex.Unlock()
+		if conn.Conn != nil {
+			conn.Conn.Close()
 		}
-	}
-}
-
-func getGpsQualityFromIndicator(indicator string) models.GpsQuality {
-	switch indicator {
-	case "0":
-		return models.FixNotValid
-	case "1":
-		return models.GpsFix
-	case "2":
-		return models.DifferentialGpsFix
-	case "3":
-		return models.NotApplicable
-	case "4":
-		return models.RtkFixed
-	case "5":
-		return models.RtkFloat
-	case "6":
-		return models.InsDeadReckoning
-	default:
-		return models.NotApplicable
+		delete(ts.sessions, sessionID)
+		ts.forgetSounding(sessionID)
 	}
 }
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"goravel/app/helpers/sounding"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// SoundingData is the latest depth of a fathometer session for the live feed
+type SoundingData struct {
+	SessionID    uint      `json:"session_id"`
+	Name         string    `json:"name"`
+	Latitude     *float64  `json:"latitude"`
+	Longitude    *float64  `json:"longitude"`
+	DepthMeters  float64   `json:"depth_meters"`
+	OffsetMeters float64   `json:"offset_meters"`
+	Source       string    `json:"source"`
+	MeasuredAt   time.Time `json:"measured_at"`
+}
+
+// processSoundingData turns a line of fathometer output into a sounding and
+// reports whether it held a depth. Soundings are stored at most once per
+// sounding_interval per session; the live value always updates.
+func (ts *TelnetService) processSoundingData(session *models.TelnetSession, data string) bool {
+	reading, err := sounding.Parse(data, ts.parser)
+	if err != nil {
+		return false
+	}
+
+	now := time.Now()
+	latitude, longitude := sessionPosition(session)
+	latest := &SoundingData{
+		SessionID:    session.ID,
+		Name:         session.Name,
+		Latitude:     latitude,
+		Longitude:    longitude,
+		DepthMeters:  reading.DepthMeters,
+		OffsetMeters: reading.OffsetMeters,
+		Source:       reading.Source,
+		MeasuredAt:   now,
+	}
+
+	ts.soundingMutex.Lock()
+	ts.latestSoundings[session.ID] = latest
+	due := now.Sub(ts.lastSoundingStored[session.ID]) >= ts.soundingInterval
+	if due {
+		ts.lastSoundingStored[session.ID] = now
+	}
+	ts.soundingMutex.Unlock()
+
+	if !due {
+		return true
+	}
+
+	record := &models.Sounding{
+		TelnetSessionID: session.ID,
+		Latitude:        latitude,
+		Longitude:       longitude,
+		DepthMeters:     reading.DepthMeters,
+		OffsetMeters:    reading.OffsetMeters,
+		Source:          reading.Source,
+		RawData:         data,
+		MeasuredAt:      now,
+	}
+	if err := facades.Orm().Query().Create(record); err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to save sounding from %s: %v", session.Name, err))
+	}
+	return true
+}
+
+// GetLatestSoundings returns the last depth of every fathometer session,
+// keyed by session ID
+func (ts *TelnetService) GetLatestSoundings() map[string]SoundingData {
+	ts.soundingMutex.Lock()
+	defer ts.soundingMutex.Unlock()
+
+	soundings := make(map[string]SoundingData, len(ts.latestSoundings))
+	for id, latest := range ts.latestSoundings {
+		soundings[strconv.FormatUint(uint64(id), 10)] = *latest
+	}
+	return soundings
+}
+
+// forgetSounding drops the live value of a session that was stopped
+func (ts *TelnetService) forgetSounding(sessionID uint) {
+	ts.soundingMutex.Lock()
+	defer ts.soundingMutex.Unlock()
+	delete(ts.latestSoundings, sessionID)
+	delete(ts.lastSoundingStored, sessionID)
+}
+
+// sessionPosition reads the fixed position of a session, given either in
+// decimal degrees or as "DD MM.mmm N"
+func sessionPosition(session *models.TelnetSession) (*float64, *float64) {
+	latitude, latOK := parseSessionCoordinate(session.Latitude)
+	longitude, lonOK := parseSessionCoordinate(session.Longitude)
+	if !latOK || !lonOK {
+		return nil, nil
+	}
+	return &latitude, &longitude
+}
+
+func parseSessionCoordinate(value *string) (float64, bool) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return 0, false
+	}
+	if decimal, err := strconv.ParseFloat(strings.TrimSpace(*value), 64); err == nil {
+		return decimal, true
+	}
+	decimal, dms := models.ParseCoordinate(*value)
+	return decimal, dms != ""
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// tlsHandshakeTimeout bounds the TLS handshake of a new connection
+const tlsHandshakeTimeout = 10 * time.Second
+
+// listen opens a TCP listener for the given tcp config section ("navigation"
+// or "sensor"), wrapped in TLS when tcp.<section>.tls.enabled is set. With a
+// client CA configured, clients must present a certificate signed by it.
+func listen(section string, address string) (net.Listener, error) {
+	prefix := "tcp." + section + ".tls."
+	if !facades.Config().GetBool(prefix+"enabled", false) {
+		return net.Listen("tcp", address)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(
+		facades.Config().GetString(prefix+"cert_file"),
+		facades.Config().GetString(prefix+"key_file"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load %s TLS certificate: %w", section, err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile := facades.Config().GetString(prefix + "client_ca_file"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read %s client CA: %w", section, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s client CA %s", section, caFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tls.Listen("tcp", address, config)
+}
+
+// peerCommonName completes the TLS handshake of a connection and returns the
+// common name of the client certificate. Plain connections and TLS
+// connections without a client certificate return an empty name.
+func peerCommonName(conn net.Conn) (string, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return "", nil
+	}
+
+	tlsConn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	defer tlsConn.SetDeadline(time.Time{})
+	if err := tlsConn.Handshake(); err != nil {
+		return "", err
+	}
+
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return "", nil
+	}
+	if certificates[0].Subject.CommonName == "" {
+		return "", errors.New("client certificate has no common name")
+	}
+	return certificates[0].Subject.CommonName, nil
+}
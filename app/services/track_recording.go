@@ -0,0 +1,131 @@
+package services
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"goravel/app/helpers/geo"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Record modes of a vessel track
+const (
+	RecordModeInterval = "interval" // A record every history_per_second seconds, the original behaviour
+	RecordModeAdaptive = "adaptive" // A record only when a deadband threshold is crossed
+)
+
+// ValidRecordMode reports whether mode is one of the RecordMode values
+func ValidRecordMode(mode string) bool {
+	return mode == RecordModeInterval || mode == RecordModeAdaptive
+}
+
+// trackRecording decides when a vessel gets a new record. In adaptive mode a
+// point is stored when the vessel moved, turned or changed speed beyond its
+// deadband, or when max_interval passed without one, so a moored vessel
+// costs a row per max_interval instead of one per history_per_second.
+// history_per_second stays the minimum spacing in both modes.
+type trackRecording struct {
+	mode           string
+	distanceMeters float64
+	courseDegrees  float64
+	speedKnots     float64
+	maxInterval    time.Duration
+}
+
+// newTrackRecording reads the defaults from the tcp.recording config section;
+// the thresholds of a vessel override them when set
+func newTrackRecording() trackRecording {
+	mode := facades.Config().GetString("tcp.recording.mode", RecordModeInterval)
+	if !ValidRecordMode(mode) {
+		mode = RecordModeInterval
+	}
+	return trackRecording{
+		mode:           mode,
+		distanceMeters: configFloat("tcp.recording.distance_meters", 25),
+		courseDegrees:  configFloat("tcp.recording.course_degrees", 10),
+		speedKnots:     configFloat("tcp.recording.speed_knots", 1),
+		maxInterval:    time.Duration(facades.Config().GetInt("tcp.recording.max_interval", 300)) * time.Second,
+	}
+}
+
+// due reports whether the current state of a buffer should be recorded. The
+// caller must hold the buffer mutex.
+func (r trackRecording) due(kapal models.Kapal, b *NMEABuffer, now time.Time) bool {
+	sinceRecord := now.Sub(b.LastRecordTime)
+	if sinceRecord < time.Duration(kapal.HistoryPerSecond)*time.Second {
+		return false
+	}
+
+	mode := kapal.RecordMode
+	if !ValidRecordMode(mode) {
+		mode = r.mode
+	}
+	if mode != RecordModeAdaptive || !b.hasRecorded {
+		return true
+	}
+
+	if maxInterval := overrideDuration(kapal.MaxRecordInterval, r.maxInterval); maxInterval > 0 && sinceRecord >= maxInterval {
+		return true
+	}
+
+	latitude, longitude := b.position()
+	if threshold := overrideFloat(kapal.DeadbandMeters, r.distanceMeters); threshold > 0 &&
+		geo.DistanceMetres(b.recordedLatitude, b.recordedLongitude, latitude, longitude) >= threshold {
+		return true
+	}
+	if threshold := overrideFloat(kapal.DeadbandKnots, r.speedKnots); threshold > 0 &&
+		math.Abs(b.SpeedInKnots-b.recordedSpeed) >= threshold {
+		return true
+	}
+	if threshold := overrideFloat(kapal.DeadbandDegrees, r.courseDegrees); threshold > 0 &&
+		angleDifference(b.track(), b.recordedCourse) >= threshold {
+		return true
+	}
+	return false
+}
+
+// recorded remembers the state a record was created from. The caller must
+// hold the buffer mutex.
+func (r trackRecording) recorded(b *NMEABuffer, now time.Time) {
+	b.recordedLatitude, b.recordedLongitude = b.position()
+	b.recordedCourse = b.track()
+	b.recordedSpeed = b.SpeedInKnots
+	b.hasRecorded = true
+	b.LastRecordTime = now
+}
+
+// angleDifference is the smallest angle between two bearings, 0 to 180
+func angleDifference(a, b float64) float64 {
+	difference := math.Mod(math.Abs(a-b), 360)
+	if difference > 180 {
+		difference = 360 - difference
+	}
+	return difference
+}
+
+func overrideFloat(value, fallback float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func overrideDuration(seconds int64, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// configFloat reads a decimal config value, which the config facade only
+// offers as a string
+func configFloat(path string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(facades.Config().GetString(path), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
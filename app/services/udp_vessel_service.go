@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"goravel/app/models"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// udpAddressRefresh is how often the IPKapal address map is reloaded
+const udpAddressRefresh = 1 * time.Minute
+
+// UDPSourceStats counts the traffic received from one source address
+type UDPSourceStats struct {
+	Address      string    `json:"address"`
+	CallSign     string    `json:"call_sign"` // Call sign of the last accepted line
+	Packets      uint64    `json:"packets"`
+	Lines        uint64    `json:"lines"`
+	Rejected     uint64    `json:"rejected"`
+	LastPacketAt time.Time `json:"last_packet_at"`
+}
+
+// UDPVesselService receives NMEA broadcast over UDP by onboard multiplexers
+// and feeds it into the TCP vessel service's pipeline. UDP has no handshake
+// and its source addresses are easily spoofed, so the listener stays closed
+// while the TCP listener requires authentication.
+type UDPVesselService struct {
+	conn    *net.UDPConn
+	mutex   sync.Mutex
+	vessels *TCPVesselService
+
+	statsMutex sync.RWMutex
+	sources    map[string]*UDPSourceStats // key is source ip:port
+
+	addressMutex  sync.Mutex
+	addresses     map[string]string // source IP to call sign, from IPKapal
+	addressesTime time.Time
+}
+
+// NewUDPVesselService creates a UDP listener that shares the vessel pipeline
+func NewUDPVesselService(vessels *TCPVesselService) *UDPVesselService {
+	return &UDPVesselService{
+		vessels:   vessels,
+		sources:   make(map[string]*UDPSourceStats),
+		addresses: make(map[string]string),
+	}
+}
+
+// Start opens the UDP listener. It does nothing when no UDP port is configured.
+func (s *UDPVesselService) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	port := facades.Config().GetString("tcp.navigation.udp_port", "")
+	if port == "" || s.conn != nil {
+		return nil
+	}
+	if facades.Config().GetBool("tcp.navigation.require_auth", false) {
+		facades.Log().Warning("UDP listener not started, it cannot authenticate vessels while tcp.navigation.require_auth is on")
+		return nil
+	}
+
+	host := facades.Config().GetString("tcp.navigation.host", "0.0.0.0")
+	address, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%s", host, port))
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenUDP("udp", address)
+	if err != nil {
+		facades.Log().Error("❌ Failed to start UDP server", err)
+		return err
+	}
+
+	s.conn = conn
+	facades.Log().Info(fmt.Sprintf("🚀 UDP Server listening on %s", address))
+
+	go s.readPackets(conn)
+	return nil
+}
+
+// readPackets reads datagrams until the connection is closed
+func (s *UDPVesselService) readPackets(conn *net.UDPConn) {
+	buffer := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.conn == nil
+			s.mutex.Unlock()
+			if closed {
+				return
+			}
+			facades.Log().Error("💥 UDP read error", err)
+			continue
+		}
+
+		s.handlePacket(addr, string(buffer[:n]))
+	}
+}
+
+// handlePacket splits a datagram into sentences. Each line is attributed to
+// the call sign of its CALLSIGN, prefix or, failing that, to the IPKapal
+// entry matching the source address.
+func (s *UDPVesselService) handlePacket(addr *net.UDPAddr, data string) {
+	stats := s.sourceStats(addr.String())
+	var lines, rejected uint64
+	var callSign string
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines++
+
+		lineCallSign := ""
+		if line[0] != '$' && line[0] != '!' {
+			prefix, rest, _ := strings.Cut(line, ",")
+			lineCallSign, line = strings.TrimSpace(prefix), strings.TrimSpace(rest)
+		}
+		if lineCallSign == "" {
+			lineCallSign = s.callSignForAddress(addr.IP.String())
+		}
+		if lineCallSign == "" || line == "" {
+			rejected++
+			continue
+		}
+
+		kapal, err := s.vessels.getKapal(lineCallSign)
+		if err != nil {
+			rejected++
+			continue
+		}
+
+		s.vessels.trackVessel(kapal)
+		s.vessels.archive.Record(lineCallSign, addr.String(), line, time.Now())
+		if s.vessels.processVesselData(*kapal, SourceUDP, line, time.Now()) != "" {
+			rejected++
+		}
+		callSign = lineCallSign
+	}
+
+	s.statsMutex.Lock()
+	stats.Packets++
+	stats.Lines += lines
+	stats.Rejected += rejected
+	stats.LastPacketAt = time.Now()
+	if callSign != "" {
+		stats.CallSign = callSign
+	}
+	s.statsMutex.Unlock()
+}
+
+// sourceStats returns the counters for a source address, creating them on first use
+func (s *UDPVesselService) sourceStats(address string) *UDPSourceStats {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+
+	stats, exists := s.sources[address]
+	if !exists {
+		facades.Log().Info("✨ New UDP source " + address)
+		stats = &UDPSourceStats{Address: address}
+		s.sources[address] = stats
+	}
+	return stats
+}
+
+// callSignForAddress maps a source IP to a call sign using the IPKapal table
+func (s *UDPVesselService) callSignForAddress(ip string) string {
+	s.addressMutex.Lock()
+	stale := time.Since(s.addressesTime) > udpAddressRefresh
+	if stale {
+		// Claimed before the query, so lookups meanwhile use the current map
+		s.addressesTime = time.Now()
+	}
+	s.addressMutex.Unlock()
+
+	if stale {
+		var entries []models.IPKapal
+		if err := facades.Orm().Query().Find(&entries); err != nil {
+			facades.Log().Error(fmt.Sprintf("Failed to load IP kapal entries: %v", err))
+		} else {
+			addresses := make(map[string]string, len(entries))
+			for _, entry := range entries {
+				addresses[entry.IP] = entry.CallSign
+			}
+			s.addressMutex.Lock()
+			s.addresses = addresses
+			s.addressMutex.Unlock()
+		}
+	}
+
+	s.addressMutex.Lock()
+	defer s.addressMutex.Unlock()
+	return s.addresses[ip]
+}
+
+// GetSourceStats returns the packet counters of every source seen, busiest first
+func (s *UDPVesselService) GetSourceStats() []UDPSourceStats {
+	s.statsMutex.RLock()
+	defer s.statsMutex.RUnlock()
+
+	stats := make([]UDPSourceStats, 0, len(s.sources))
+	for _, source := range s.sources {
+		stats = append(stats, *source)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Packets > stats[j].Packets
+	})
+	return stats
+}
+
+// Stop closes the UDP listener
+func (s *UDPVesselService) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	conn := s.conn
+	s.conn = nil
+	return conn.Close()
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Acknowledgement modes of the navigation listener. A vessel's own ack_mode
+// overrides the listener default once the connection has identified.
+const (
+	AckModeNone = "none" // Send nothing back, for metered satellite links
+	AckModeEcho = "echo" // Send every line back unchanged, the original behaviour
+	AckModeAck  = "ack"  // Send an ACK line with the sequence number and verdict
+)
+
+// Reasons carried in a rejecting ACK line. A device may resend the line after
+// SERVER_ERROR; the other reasons need a configuration change first.
+const (
+	rejectAuth            = "AUTH"
+	rejectUnknownCallSign = "UNKNOWN_CALLSIGN"
+	rejectNoCallSign      = "NO_CALLSIGN"
+	rejectChecksum        = "CHECKSUM"
+	rejectInvalid         = "INVALID"
+	rejectTooLong         = "TOO_LONG"
+	rejectServerError     = "SERVER_ERROR"
+)
+
+// ValidAckMode reports whether mode is one of the AckMode values
+func ValidAckMode(mode string) bool {
+	switch mode {
+	case AckModeNone, AckModeEcho, AckModeAck:
+		return true
+	}
+	return false
+}
+
+// formatAck builds the reply to the line with the given sequence number, e.g.
+// "ACK,12,OK" or "ACK,13,REJECT,CHECKSUM". Sequence numbers count the
+// non-empty lines of the connection starting at 1, so a device can match
+// replies without adding numbers of its own.
+func formatAck(sequence uint64, reason string) string {
+	if reason == "" {
+		return fmt.Sprintf("ACK,%d,OK", sequence)
+	}
+	return fmt.Sprintf("ACK,%d,REJECT,%s", sequence, reason)
+}
+
+// normalizeAckMode lowercases a configured mode and falls back to echo for
+// anything unknown
+func normalizeAckMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !ValidAckMode(mode) {
+		return AckModeEcho
+	}
+	return mode
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"fmt"
+	"goravel/app/helpers/secret"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// verifyVesselToken checks a handshake token against the vessel's device
+// secret, then against the tokens issued per IPKapal entry
+func verifyVesselToken(kapal *models.Kapal, token string) bool {
+	if secret.Matches(kapal.DeviceSecretHash, token) {
+		return true
+	}
+
+	var entries []models.IPKapal
+	err := facades.Orm().Query().
+		Where("call_sign = ?", kapal.CallSign).
+		Where("token_hash IS NOT NULL AND token_hash <> ''").
+		Find(&entries)
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to load IP tokens for %s: %v", kapal.CallSign, err))
+		return false
+	}
+
+	for _, entry := range entries {
+		if secret.Matches(entry.TokenHash, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// errLineTooLong is returned for a line that exceeded the maximum length; the
+// whole line is discarded and reading continues with the next one
+var errLineTooLong = errors.New("line exceeds maximum length")
+
+// defaultMaxLineLength leaves room for a call-sign prefix in front of the
+// 82 character NMEA limit and for long proprietary sentences
+const defaultMaxLineLength = 1024
+
+// vesselConnection holds the state of one TCP navigation connection
+type vesselConnection struct {
+	conn          net.Conn
+	reader        *bufio.Reader
+	maxLineLength int
+
+	// callSign is set by a handshake line or the latest CALLSIGN, prefix and
+	// applies to every unprefixed sentence that follows
+	callSign string
+	// authenticated is set once a CALLSIGN,TOKEN handshake or a client
+	// certificate has been verified; the call sign can no longer change then
+	authenticated bool
+	certificateCN string
+
+	connectedAt   time.Time
+	sequence      uint64 // Non-empty lines received, including dropped ones; numbers ACK lines
+	linesRead     uint64
+	linesRejected uint64
+	warnedNoIdent bool
+}
+
+func newVesselConnection(conn net.Conn, maxLineLength int) *vesselConnection {
+	if maxLineLength <= 0 {
+		maxLineLength = defaultMaxLineLength
+	}
+	return &vesselConnection{
+		conn:          conn,
+		reader:        bufio.NewReaderSize(conn, maxLineLength),
+		maxLineLength: maxLineLength,
+		connectedAt:   time.Now(),
+	}
+}
+
+// readLine returns the next line without its line ending. Partial lines are
+// buffered until the rest arrives, however the data was split into segments.
+// A final line without a newline is returned together with the read error.
+func (c *vesselConnection) readLine() (string, error) {
+	tooLong := false
+	for {
+		chunk, err := c.reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// Keep discarding until the end of the oversized line
+			tooLong = true
+			continue
+		}
+		if tooLong {
+			c.sequence++
+			c.linesRejected++
+			if err != nil {
+				return "", err
+			}
+			return "", errLineTooLong
+		}
+
+		line := strings.TrimRight(string(chunk), "\r\n")
+		if line != "" {
+			c.sequence++
+			c.linesRead++
+		}
+		return line, err
+	}
+}
+
+// remoteAddr returns the peer address for logging
+func (c *vesselConnection) remoteAddr() string {
+	return c.conn.RemoteAddr().String()
+}
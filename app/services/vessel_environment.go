@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"goravel/app/helpers/nmea"
+	"goravel/app/models"
+	"strings"
+	"time"
+)
+
+// EnvironmentReading is the latest value of one environmental or transducer measurement
+type EnvironmentReading struct {
+	Value     float64   `json:"value"`
+	Unit      string    `json:"unit"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// applyEnvironment stores the readings of an MTW, MWV, MDA or XDR sentence
+// under stable names. The caller must hold the buffer mutex.
+func (b *NMEABuffer) applyEnvironment(sentence nmea.Sentence, now time.Time) {
+	if b.Environment == nil {
+		b.Environment = make(map[string]EnvironmentReading)
+	}
+	set := func(name string, value float64, unit string, source string) {
+		b.Environment[name] = EnvironmentReading{Value: value, Unit: unit, Source: source, UpdatedAt: now}
+	}
+
+	switch s := sentence.(type) {
+	case nmea.MTW:
+		if s.HasTemperature {
+			set("water_temperature", s.Temperature, "C", nmea.TypeMTW)
+		}
+	case nmea.MWV:
+		if !s.Valid {
+			return
+		}
+		// R is the apparent wind, T the true wind, both as an angle off the bow
+		suffix := "relative"
+		if s.Reference == "T" {
+			suffix = "true"
+		}
+		if s.HasAngle {
+			set("wind_angle_"+suffix, s.Angle, "deg", nmea.TypeMWV)
+		}
+		if s.HasSpeed {
+			set("wind_speed_"+suffix, s.SpeedKnots, "kn", nmea.TypeMWV)
+		}
+	case nmea.MDA:
+		if s.HasPressure {
+			set("barometric_pressure", s.PressureBar, "bar", nmea.TypeMDA)
+		}
+		if s.HasAirTemperature {
+			set("air_temperature", s.AirTemperature, "C", nmea.TypeMDA)
+		}
+		if s.HasWaterTemperature {
+			set("water_temperature", s.WaterTemperature, "C", nmea.TypeMDA)
+		}
+		if s.HasRelativeHumidity {
+			set("relative_humidity", s.RelativeHumidity, "%", nmea.TypeMDA)
+		}
+		if s.HasDewPoint {
+			set("dew_point", s.DewPoint, "C", nmea.TypeMDA)
+		}
+		if s.HasWindDirection {
+			set("wind_direction_true", s.WindDirection, "deg", nmea.TypeMDA)
+		}
+		if s.HasWindSpeed {
+			set("wind_speed_true", s.WindSpeedKnots, "kn", nmea.TypeMDA)
+		}
+	case nmea.XDR:
+		for i, m := range s.Measurements {
+			name := strings.ToLower(m.Name)
+			if name == "" {
+				name = fmt.Sprintf("%s%d", strings.ToLower(m.Type), i+1)
+			}
+			set("xdr_"+name, m.Value, m.Unit, nmea.TypeXDR)
+		}
+	}
+}
+
+// environmentSnapshot copies the environment readings so they can be used
+// outside the buffer mutex
+func (b *NMEABuffer) environmentSnapshot() map[string]EnvironmentReading {
+	if len(b.Environment) == 0 {
+		return nil
+	}
+
+	snapshot := make(map[string]EnvironmentReading, len(b.Environment))
+	for name, reading := range b.Environment {
+		snapshot[name] = reading
+	}
+	return snapshot
+}
+
+// telemetryRecords returns the environment readings that changed since the
+// vessel's last record. The caller holds the buffer mutex.
+func telemetryRecords(callSign string, buffer *NMEABuffer, now time.Time) []models.VesselTelemetryRecord {
+	var records []models.VesselTelemetryRecord
+	for name, reading := range buffer.Environment {
+		if !reading.UpdatedAt.After(buffer.LastRecordTime) {
+			continue
+		}
+		records = append(records, models.VesselTelemetryRecord{
+			CallSign:  callSign,
+			Name:      name,
+			Value:     reading.Value,
+			Unit:      reading.Unit,
+			Source:    reading.Source,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return records
+}
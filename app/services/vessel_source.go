@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goravel/app/helpers/nmea"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Roles a source competes for. Only the selected source of a role may
+// update it, so two live GNSS receivers never interleave positions.
+const (
+	rolePosition = "position"
+	roleHeading  = "heading"
+	roleSpeed    = "speed"
+	roleDepth    = "depth"
+)
+
+// Names of the sources feeding the vessel pipeline. Outbound connections
+// carry the ID of their entry, e.g. "telnet:3" or "ip:12".
+const (
+	SourceTCP     = "tcp"
+	SourceUDP     = "udp"
+	SourceIP      = "ip"
+	SourceTelnet  = "telnet"
+	SourceSignalK = "signalk"
+	SourceReplay  = "replay"
+)
+
+var sourceNamePattern = regexp.MustCompile(`^(tcp|udp|replay|(ip|telnet|signalk):\d+)$`)
+
+// sourceName names an outbound source by its entry ID
+func sourceName(kind string, id uint) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// ParseSourcePriority splits a comma separated priority list such as
+// "telnet:3,tcp" and returns the first entry that is not a source name
+func ParseSourcePriority(priority string) ([]string, string) {
+	var sources []string
+	for _, source := range strings.Split(priority, ",") {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" {
+			continue
+		}
+		if !sourceNamePattern.MatchString(source) {
+			return nil, source
+		}
+		sources = append(sources, source)
+	}
+	return sources, ""
+}
+
+// sentenceRole returns the role a sentence fills, empty for sentences any
+// source may deliver such as environment readings
+func sentenceRole(sentence nmea.Sentence) string {
+	switch sentence.(type) {
+	case nmea.GGA, nmea.RMC:
+		return rolePosition
+	case nmea.HDT, nmea.HDG, nmea.HDM:
+		return roleHeading
+	case nmea.VTG:
+		return roleSpeed
+	case nmea.DBT, nmea.DPT, nmea.DBS:
+		return roleDepth
+	}
+	return ""
+}
+
+// SourceSelector picks one source per vessel and role. The highest ranked
+// live source wins; sources missing from the vessel's priority list rank
+// last and keep the role until they go stale, so equal sources do not flap.
+// A better ranked source that comes back takes over once it has been live
+// for failbackAfter.
+type SourceSelector struct {
+	staleAfter    time.Duration
+	failbackAfter time.Duration
+
+	mutex sync.Mutex
+	roles map[string]map[string]*sourceRole // keys are CallSign and role
+}
+
+// sourceRole tracks the sources seen for one role of a vessel
+type sourceRole struct {
+	selected  string
+	changedAt time.Time
+	failovers uint64
+	sources   map[string]*sourceActivity
+}
+
+type sourceActivity struct {
+	liveSince time.Time // Start of the current run without gaps longer than staleAfter
+	lastSeen  time.Time
+}
+
+// SourceState reports the selected source of one role of a vessel
+type SourceState struct {
+	CallSign  string           `json:"call_sign"`
+	Role      string           `json:"role"`
+	Selected  string           `json:"selected"`
+	ChangedAt time.Time        `json:"changed_at"`
+	Failovers uint64           `json:"failovers"`
+	Sources   []SourceLastSeen `json:"sources"`
+}
+
+// SourceLastSeen reports when a candidate source last delivered the role
+type SourceLastSeen struct {
+	Source   string    `json:"source"`
+	LastSeen time.Time `json:"last_seen"`
+	Stale    bool      `json:"stale"`
+}
+
+// NewSourceSelector creates the selector from the tcp.failover config section
+func NewSourceSelector() *SourceSelector {
+	return &SourceSelector{
+		staleAfter:    time.Duration(facades.Config().GetInt("tcp.failover.stale_after", 10)) * time.Second,
+		failbackAfter: time.Duration(facades.Config().GetInt("tcp.failover.failback_after", 30)) * time.Second,
+		roles:         make(map[string]map[string]*sourceRole),
+	}
+}
+
+// Accept records that source delivered role for a vessel and reports whether
+// it is the selected source. priority is the vessel's source_priority list.
+func (s *SourceSelector) Accept(callSign string, priority string, role string, source string, now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	roles, exists := s.roles[callSign]
+	if !exists {
+		roles = make(map[string]*sourceRole)
+		s.roles[callSign] = roles
+	}
+	state, exists := roles[role]
+	if !exists {
+		state = &sourceRole{sources: make(map[string]*sourceActivity)}
+		roles[role] = state
+	}
+
+	activity, exists := state.sources[source]
+	if !exists {
+		activity = &sourceActivity{}
+		state.sources[source] = activity
+	}
+	if now.Sub(activity.lastSeen) > s.staleAfter {
+		activity.liveSince = now
+	}
+	activity.lastSeen = now
+
+	switch {
+	case state.selected == source:
+		return true
+	case state.selected == "":
+		state.selected, state.changedAt = source, now
+		return true
+	}
+
+	current := state.sources[state.selected]
+	if now.Sub(current.lastSeen) > s.staleAfter {
+		s.switchSource(callSign, role, state, source, "stale for "+now.Sub(current.lastSeen).Round(time.Second).String(), now)
+		return true
+	}
+
+	ranks, _ := ParseSourcePriority(priority)
+	if sourceRank(ranks, source) < sourceRank(ranks, state.selected) && now.Sub(activity.liveSince) >= s.failbackAfter {
+		s.switchSource(callSign, role, state, source, "higher priority", now)
+		return true
+	}
+	return false
+}
+
+// switchSource hands a role to another source. The caller holds the mutex.
+func (s *SourceSelector) switchSource(callSign string, role string, state *sourceRole, source string, reason string, now time.Time) {
+	facades.Log().Info(fmt.Sprintf("🔀 %s %s source %s -> %s (%s)", callSign, role, state.selected, source, reason))
+	state.selected, state.changedAt = source, now
+	state.failovers++
+}
+
+// sourceRank is the position of a source in the priority list, sources that
+// are not listed rank after all listed ones
+func sourceRank(priority []string, source string) int {
+	for rank, listed := range priority {
+		if listed == source {
+			return rank
+		}
+	}
+	return len(priority)
+}
+
+// Forget drops the sources of a vessel that disconnected
+func (s *SourceSelector) Forget(callSign string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.roles, callSign)
+}
+
+// States returns the selected source of every vessel role, by call sign and role
+func (s *SourceSelector) States() []SourceState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	states := make([]SourceState, 0, len(s.roles))
+	for callSign, roles := range s.roles {
+		for role, state := range roles {
+			entry := SourceState{
+				CallSign:  callSign,
+				Role:      role,
+				Selected:  state.selected,
+				ChangedAt: state.changedAt,
+				Failovers: state.failovers,
+			}
+			for source, activity := range state.sources {
+				entry.Sources = append(entry.Sources, SourceLastSeen{
+					Source:   source,
+					LastSeen: activity.lastSeen,
+					Stale:    now.Sub(activity.lastSeen) > s.staleAfter,
+				})
+			}
+			sort.Slice(entry.Sources, func(i, j int) bool { return entry.Sources[i].Source < entry.Sources[j].Source })
+			states = append(states, entry)
+		}
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].CallSign != states[j].CallSign {
+			return states[i].CallSign < states[j].CallSign
+		}
+		return states[i].Role < states[j].Role
+	})
+	return states
+}
+
+// GetSourceStates returns the selected source of every role of the live vessels
+func (s *TCPVesselService) GetSourceStates() []SourceState {
+	return s.sources.States()
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"goravel/app/helpers/geo"
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// voyageSaveInterval limits how often the statistics of a voyage underway are
+// written; a voyage is always saved when it starts and ends
+const voyageSaveInterval = 1 * time.Minute
+
+// voyageSweepInterval is how often CloseStale looks for voyages left underway
+// in the database by vessels that have not reported since a restart
+const voyageSweepInterval = 1 * time.Minute
+
+// VoyageTracker splits the records of each vessel into voyages. A voyage
+// starts once the vessel has made way for departAfter and ends when it has
+// been stationary for arriveAfter, or when no record arrived for gapAfter.
+// Records taken while a voyage is underway are linked to it. The mutex only
+// guards the state in memory; voyages are read and written once it is
+// released, so a slow database does not hold up the records of other vessels.
+type VoyageTracker struct {
+	enabled     bool
+	movingKnots float64 // Speed from which a vessel counts as making way
+	departAfter time.Duration
+	arriveAfter time.Duration
+	gapAfter    time.Duration
+	writer      *RecordWriter
+
+	mutex   sync.Mutex
+	vessels map[string]*vesselVoyage // key is CallSign
+	sweptAt time.Time
+}
+
+// vesselVoyage is the voyage state of one vessel
+type vesselVoyage struct {
+	voyage    *models.Voyage // Open voyage, nil while stationary
+	savedAt   time.Time
+	departing bool // The voyage is being created
+
+	movingSince      time.Time // Start of the current run above movingKnots, while no voyage is open
+	movingLatitude   float64
+	movingLongitude  float64
+	stoppedSince     time.Time // Start of the current stationary run, while a voyage is open
+	stoppedLatitude  float64
+	stoppedLongitude float64
+	lastAt           time.Time
+}
+
+// NewVoyageTracker creates the tracker from the tcp.voyages config section.
+// writer is flushed before records taken on departure are linked to the new
+// voyage.
+func NewVoyageTracker(writer *RecordWriter) *VoyageTracker {
+	return &VoyageTracker{
+		enabled:     facades.Config().GetBool("tcp.voyages.enabled", true),
+		movingKnots: configFloat("tcp.voyages.moving_knots", 1),
+		departAfter: time.Duration(facades.Config().GetInt("tcp.voyages.depart_after", 120)) * time.Second,
+		arriveAfter: time.Duration(facades.Config().GetInt("tcp.voyages.arrive_after", 900)) * time.Second,
+		gapAfter:    time.Duration(facades.Config().GetInt("tcp.voyages.gap_after", 3600)) * time.Second,
+		writer:      writer,
+		vessels:     make(map[string]*vesselVoyage),
+	}
+}
+
+// Observe feeds a vessel record into the tracker and returns the ID of the
+// voyage the record belongs to, nil while the vessel is stationary. It waits
+// on the database, so the caller must not hold the buffer mutex.
+func (t *VoyageTracker) Observe(callSign string, latitude, longitude, speedKnots float64, now time.Time) *uint64 {
+	if t == nil || !t.enabled {
+		return nil
+	}
+
+	t.mutex.Lock()
+	_, known := t.vessels[callSign]
+	t.mutex.Unlock()
+	var resumed *models.Voyage
+	if !known {
+		// Only the first record of a vessel since startup asks the database
+		resumed = t.resume(callSign)
+	}
+
+	t.mutex.Lock()
+	vessel, exists := t.vessels[callSign]
+	if !exists {
+		vessel = &vesselVoyage{voyage: resumed}
+		t.vessels[callSign] = vessel
+	}
+	id, work := t.observe(callSign, vessel, latitude, longitude, speedKnots, now)
+	t.mutex.Unlock()
+
+	t.write(work)
+	if work.departure != nil {
+		id = t.depart(vessel, work.departure, latitude, longitude, speedKnots, now)
+	}
+	return id
+}
+
+// voyageWork is the database work a change of the tracker state leaves for
+// after the mutex is released
+type voyageWork struct {
+	saves     []models.Voyage // Copies of the voyages to write, in order
+	departure *models.Voyage  // Voyage to create
+}
+
+// observe applies a record to the state of a vessel. The caller holds the
+// mutex.
+func (t *VoyageTracker) observe(callSign string, vessel *vesselVoyage, latitude, longitude, speedKnots float64, now time.Time) (*uint64, voyageWork) {
+	var work voyageWork
+	if vessel.voyage != nil && now.Sub(vessel.voyage.LastRecordAt) > t.gapAfter {
+		t.end(vessel, models.VoyageEndDataGap, vessel.voyage.LastRecordAt, vessel.voyage.EndLatitude, vessel.voyage.EndLongitude, &work)
+	}
+	if !vessel.lastAt.IsZero() && now.Sub(vessel.lastAt) > t.gapAfter {
+		vessel.movingSince = time.Time{}
+	}
+	vessel.lastAt = now
+
+	if speedKnots >= t.movingKnots {
+		vessel.stoppedSince = time.Time{}
+		if vessel.voyage == nil && !vessel.departing {
+			if vessel.movingSince.IsZero() {
+				vessel.movingSince, vessel.movingLatitude, vessel.movingLongitude = now, latitude, longitude
+			}
+			if now.Sub(vessel.movingSince) >= t.departAfter {
+				vessel.departing = true
+				work.departure = t.newVoyage(callSign, vessel)
+			}
+		}
+	} else {
+		vessel.movingSince = time.Time{}
+		if vessel.voyage != nil && vessel.stoppedSince.IsZero() {
+			vessel.stoppedSince, vessel.stoppedLatitude, vessel.stoppedLongitude = now, latitude, longitude
+		}
+	}
+
+	if vessel.voyage == nil {
+		return nil, work
+	}
+
+	t.advance(vessel, latitude, longitude, speedKnots, now, &work)
+	id := vessel.voyage.ID
+	if !vessel.stoppedSince.IsZero() && now.Sub(vessel.stoppedSince) >= t.arriveAfter {
+		t.end(vessel, models.VoyageEndArrival, vessel.stoppedSince, vessel.stoppedLatitude, vessel.stoppedLongitude, &work)
+	}
+	return &id, work
+}
+
+// resume picks up the voyage a vessel was on before a restart
+func (t *VoyageTracker) resume(callSign string) *models.Voyage {
+	var voyage models.Voyage
+	err := facades.Orm().Query().
+		Where("call_sign = ?", callSign).
+		Where("status = ?", models.VoyageUnderway).
+		Order("started_at DESC").
+		First(&voyage)
+	if err != nil || voyage.ID == 0 {
+		return nil
+	}
+	return &voyage
+}
+
+// newVoyage returns a voyage starting at the position where the vessel began
+// making way. The caller holds the mutex.
+func (t *VoyageTracker) newVoyage(callSign string, vessel *vesselVoyage) *models.Voyage {
+	return &models.Voyage{
+		CallSign:       callSign,
+		Status:         models.VoyageUnderway,
+		StartedAt:      vessel.movingSince,
+		StartLatitude:  vessel.movingLatitude,
+		StartLongitude: vessel.movingLongitude,
+		EndLatitude:    vessel.movingLatitude,
+		EndLongitude:   vessel.movingLongitude,
+		LastRecordAt:   vessel.movingSince,
+	}
+}
+
+// depart creates the voyage a vessel started, opens it for the vessel and
+// links the records taken since. It returns the ID of the voyage, nil when
+// it could not be created and the next record should try again.
+func (t *VoyageTracker) depart(vessel *vesselVoyage, voyage *models.Voyage, latitude, longitude, speedKnots float64, now time.Time) *uint64 {
+	err := facades.Orm().Query().Create(voyage)
+
+	t.mutex.Lock()
+	vessel.departing = false
+	if err != nil {
+		t.mutex.Unlock()
+		facades.Log().Error(fmt.Sprintf("Failed to start voyage of %s: %v", voyage.CallSign, err))
+		return nil
+	}
+	vessel.voyage, vessel.savedAt = voyage, now
+	var work voyageWork
+	t.advance(vessel, latitude, longitude, speedKnots, now, &work)
+	id := voyage.ID
+	t.mutex.Unlock()
+
+	t.write(work)
+	facades.Log().Info(fmt.Sprintf("⚓ %s departed at %.5f, %.5f, voyage %d", voyage.CallSign, voyage.StartLatitude, voyage.StartLongitude, id))
+	t.link(voyage.CallSign, id, voyage.StartedAt)
+	return &id
+}
+
+// link assigns the records of the run up to departure to a new voyage
+func (t *VoyageTracker) link(callSign string, voyageID uint64, startedAt time.Time) {
+	// Those records are queued or written already
+	t.writer.Flush()
+	_, err := facades.Orm().Query().Model(&models.VesselRecord{}).
+		Where("call_sign = ?", callSign).
+		Where("voyage_id IS NULL").
+		Where("created_at >= ?", startedAt).
+		Update("voyage_id", voyageID)
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to link records to voyage %d: %v", voyageID, err))
+	}
+}
+
+// advance adds a record to the open voyage's track and statistics. The
+// caller holds the mutex.
+func (t *VoyageTracker) advance(vessel *vesselVoyage, latitude, longitude, speedKnots float64, now time.Time, work *voyageWork) {
+	voyage := vessel.voyage
+	voyage.DistanceMeters += geo.DistanceMetres(voyage.EndLatitude, voyage.EndLongitude, latitude, longitude)
+	voyage.EndLatitude, voyage.EndLongitude = latitude, longitude
+	voyage.LastRecordAt = now
+	if speedKnots > voyage.MaxSpeedKnots {
+		voyage.MaxSpeedKnots = speedKnots
+	}
+	if hours := now.Sub(voyage.StartedAt).Hours(); hours > 0 {
+		voyage.AvgSpeedKnots = voyage.DistanceMeters / geo.MetresPerNauticalMile / hours
+	}
+
+	if now.Sub(vessel.savedAt) >= voyageSaveInterval {
+		work.saves = append(work.saves, *voyage)
+		vessel.savedAt = now
+	}
+}
+
+// end closes the open voyage of a vessel. The caller holds the mutex.
+func (t *VoyageTracker) end(vessel *vesselVoyage, reason string, endedAt time.Time, latitude, longitude float64, work *voyageWork) {
+	voyage := vessel.voyage
+	voyage.Status, voyage.EndReason = models.VoyageCompleted, reason
+	voyage.EndedAt = &endedAt
+	voyage.EndLatitude, voyage.EndLongitude = latitude, longitude
+	if hours := endedAt.Sub(voyage.StartedAt).Hours(); hours > 0 {
+		voyage.AvgSpeedKnots = voyage.DistanceMeters / geo.MetresPerNauticalMile / hours
+	}
+	work.saves = append(work.saves, *voyage)
+	facades.Log().Info(fmt.Sprintf("⚓ %s voyage %d ended (%s) after %.1f nm", voyage.CallSign, voyage.ID, reason, voyage.DistanceMeters/geo.MetresPerNauticalMile))
+
+	vessel.voyage = nil
+	vessel.stoppedSince = time.Time{}
+}
+
+// write saves the voyage copies of work. Copies are written by whichever
+// goroutine took them, so only a voyage still underway is updated; an older
+// copy cannot reopen a voyage that has ended since.
+func (t *VoyageTracker) write(work voyageWork) {
+	for _, voyage := range work.saves {
+		_, err := facades.Orm().Query().Model(&models.Voyage{}).
+			Where("id = ?", voyage.ID).
+			Where("status = ?", models.VoyageUnderway).
+			Update(map[string]any{
+				"status":          voyage.Status,
+				"end_reason":      voyage.EndReason,
+				"ended_at":        voyage.EndedAt,
+				"end_latitude":    voyage.EndLatitude,
+				"end_longitude":   voyage.EndLongitude,
+				"last_record_at":  voyage.LastRecordAt,
+				"distance_meters": voyage.DistanceMeters,
+				"max_speed_knots": voyage.MaxSpeedKnots,
+				"avg_speed_knots": voyage.AvgSpeedKnots,
+			})
+		if err != nil {
+			facades.Log().Error(fmt.Sprintf("Failed to save voyage %d of %s: %v", voyage.ID, voyage.CallSign, err))
+		}
+	}
+}
+
+// CloseStale ends the voyages of vessels that sent no record for gapAfter,
+// so a vessel that went silent does not stay underway until it returns
+func (t *VoyageTracker) CloseStale(now time.Time) {
+	if t == nil || !t.enabled {
+		return
+	}
+
+	var work voyageWork
+	t.mutex.Lock()
+	for _, vessel := range t.vessels {
+		if vessel.voyage != nil && now.Sub(vessel.voyage.LastRecordAt) > t.gapAfter {
+			t.end(vessel, models.VoyageEndDataGap, vessel.voyage.LastRecordAt, vessel.voyage.EndLatitude, vessel.voyage.EndLongitude, &work)
+		}
+	}
+	sweep := now.Sub(t.sweptAt) >= voyageSweepInterval
+	if sweep {
+		t.sweptAt = now
+	}
+	t.mutex.Unlock()
+	t.write(work)
+
+	if !sweep {
+		return
+	}
+	var voyages []models.Voyage
+	err := facades.Orm().Query().
+		Where("status = ?", models.VoyageUnderway).
+		Where("last_record_at < ?", now.Add(-t.gapAfter)).
+		Find(&voyages)
+	if err != nil {
+		facades.Log().Error(fmt.Sprintf("Failed to fetch stale voyages: %v", err))
+		return
+	}
+
+	work = voyageWork{}
+	t.mutex.Lock()
+	for i := range voyages {
+		voyage := &voyages[i]
+		if vessel, exists := t.vessels[voyage.CallSign]; exists && vessel.voyage != nil && vessel.voyage.ID == voyage.ID {
+			continue // Tracked in memory, where LastRecordAt is newer
+		}
+		t.end(&vesselVoyage{voyage: voyage}, models.VoyageEndDataGap, voyage.LastRecordAt, voyage.EndLatitude, voyage.EndLongitude, &work)
+	}
+	t.mutex.Unlock()
+	t.write(work)
+}
+
+// Save writes the statistics of every voyage underway, so a restart resumes
+// them where they were
+func (t *VoyageTracker) Save() {
+	if t == nil || !t.enabled {
+		return
+	}
+
+	var work voyageWork
+	t.mutex.Lock()
+	for _, vessel := range t.vessels {
+		if vessel.voyage != nil {
+			work.saves = append(work.saves, *vessel.voyage)
+		}
+	}
+	t.mutex.Unlock()
+	t.write(work)
+}
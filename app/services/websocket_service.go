@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"goravel/app/models"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,6 +26,9 @@ type WebSocketService struct {
 
 	*TCPVesselService
 	*TCPSensorService
+	aisService *AISService
+	// telnetService is resolved lazily, TelnetServiceProvider registers after this service is created
+	telnetService *TelnetService
 
 	kapalCache      map[string]*models.Kapal
 	sensorCache     map[string]*models.Sensor
@@ -64,22 +68,31 @@ type VesselData struct {
 
 // TelemetryData represents real-time vessel telemetry
 type TelemetryData struct {
-	CallSign                    string    `json:"call_sign"`
-	Latitude                    string    `json:"latitude"`
-	Longitude                   string    `json:"longitude"`
-	LatitudeDMS                 string    `json:"latitude_dms"`
-	LongitudeDMS                string    `json:"longitude_dms"`
-	LatitudeDecimal             float64   `json:"latitude_decimal"`
-	LongitudeDecimal            float64   `json:"longitude_decimal"`
-	HeadingDegree               float64   `json:"heading_degree"`
-	SpeedInKnots                float64   `json:"speed_in_knots"`
-	SpeedInKmh                  float64   `json:"speed_in_kmh"`
-	GpsQualityIndicator         string    `json:"gps_quality_indicator"`
-	WaterDepth                  float64   `json:"water_depth"`
-	TelnetStatus                string    `json:"telnet_status"`
-	LastUpdate                  time.Time `json:"last_update"`
-	CurrentKnotPerLiterGasoline float64   `json:"current_knot_per_liter_gasoline"`
-	FuelEfficiencyStatus        string    `json:"fuel_efficiency_status"`
+	CallSign                    string                        `json:"call_sign"`
+	Latitude                    string                        `json:"latitude"`
+	Longitude                   string                        `json:"longitude"`
+	LatitudeDMS                 string                        `json:"latitude_dms"`
+	LongitudeDMS                string                        `json:"longitude_dms"`
+	LatitudeDecimal             float64                       `json:"latitude_decimal"`
+	LongitudeDecimal            float64                       `json:"longitude_decimal"`
+	HeadingDegree               float64                       `json:"heading_degree"`
+	RawHeadingDegree            float64                       `json:"raw_heading_degree"`
+	HeadingSource               string                        `json:"heading_source"`
+	PositionSource              string                        `json:"position_source"` // Pipeline source of the position, e.g. "tcp" or "telnet:3"
+	Estimated                   bool                          `json:"estimated"`       // Position projected by dead reckoning since the last fix
+	HeadingCalibration          float64                       `json:"heading_calibration"`
+	SpeedInKnots                float64                       `json:"speed_in_knots"`
+	SpeedInKmh                  float64                       `json:"speed_in_kmh"`
+	CourseOverGround            float64                       `json:"course_over_ground"`
+	GpsQualityIndicator         string                        `json:"gps_quality_indicator"`
+	WaterDepth                  float64                       `json:"water_depth"`
+	TelnetStatus                string                        `json:"telnet_status"`
+	LastUpdate                  time.Time                     `json:"last_update"`
+	FixTime                     *time.Time                    `json:"fix_time"`      // UTC time reported by the GNSS receiver
+	ClockSkewMs                 int64                         `json:"clock_skew_ms"` // Arrival time minus receiver time
+	Environment                 map[string]EnvironmentReading `json:"environment"`   // Water temperature, wind, MDA and XDR readings
+	CurrentKnotPerLiterGasoline float64                       `json:"current_knot_per_liter_gasoline"`
+	FuelEfficiencyStatus        string                        `json:"fuel_efficiency_status"`
 }
 
 // NavigationData combines vessel and telemetry data
@@ -100,10 +113,34 @@ type SensorData struct {
 	ConnectionStatus string     `json:"connection_status"`
 }
 
+// AisContactData represents a third-party AIS target
+type AisContactData struct {
+	MMSI             uint32    `json:"mmsi"`
+	Name             string    `json:"name"`
+	CallSign         string    `json:"call_sign"`
+	ShipType         int       `json:"ship_type"`
+	AisClass         string    `json:"ais_class"`
+	Destination      string    `json:"destination"`
+	LatitudeDecimal  float64   `json:"latitude_decimal"`
+	LongitudeDecimal float64   `json:"longitude_decimal"`
+	SpeedOverGround  float64   `json:"speed_over_ground"`
+	CourseOverGround float64   `json:"course_over_ground"`
+	TrueHeading      *int      `json:"true_heading"`
+	NavigationStatus int       `json:"navigation_status"`
+	LengthM          int       `json:"length_m"`
+	WidthM           int       `json:"width_m"`
+	OwnShip          bool      `json:"own_ship"`
+	ReceivedBy       string    `json:"received_by"`
+	LastUpdate       time.Time `json:"last_update"`
+}
+
 // WebSocketResponse is the main response structure sent to clients
 type WebSocketResponse struct {
-	Navigation map[string]NavigationData `json:"navigation"`
-	Sensors    map[string]SensorData     `json:"sensors"`
+	Navigation     map[string]NavigationData `json:"navigation"`
+	Sensors        map[string]SensorData     `json:"sensors"`
+	AisContacts    map[string]AisContactData `json:"ais_contacts"`
+	TelnetSessions []TelnetSessionState      `json:"telnet_sessions"`
+	Soundings      map[string]SoundingData   `json:"soundings"` // Latest depth per fathometer session
 }
 
 var upgrader = websocket.Upgrader{
@@ -113,7 +150,7 @@ var upgrader = websocket.Upgrader{
 }
 
 // NewWebSocketService creates a new WebSocket service
-func NewWebSocketService(tcpService *TCPVesselService, sensorService *TCPSensorService) *WebSocketService {
+func NewWebSocketService(tcpService *TCPVesselService, sensorService *TCPSensorService, aisService *AISService) *WebSocketService {
 	ctx, cancel := context.WithCancel(context.Background())
 	ws := &WebSocketService{
 		ctx:              ctx,
@@ -123,6 +160,7 @@ func NewWebSocketService(tcpService *TCPVesselService, sensorService *TCPSensorS
 		unregister:       make(chan *websocket.Conn),
 		TCPVesselService: tcpService,
 		TCPSensorService: sensorService,
+		aisService:       aisService,
 		kapalCache:       make(map[string]*models.Kapal),
 		sensorCache:      make(map[string]*models.Sensor),
 	}
@@ -232,8 +270,11 @@ func (ws *WebSocketService) broadcastData() {
 			}
 
 			response := WebSocketResponse{
-				Navigation: ws.getNavigationData(),
-				Sensors:    ws.getSensorData(),
+				Navigation:     ws.getNavigationData(),
+				Sensors:        ws.getSensorData(),
+				AisContacts:    ws.getAISContactData(),
+				TelnetSessions: ws.getTelnetSessionData(),
+				Soundings:      ws.getSoundingData(),
 			}
 
 			jsonData, err := json.Marshal(response)
@@ -249,6 +290,41 @@ func (ws *WebSocketService) broadcastData() {
 	}
 }
 
+// getTelnetSessionData returns the telnet session states, or an empty list
+// when the telnet service is not registered
+func (ws *WebSocketService) getTelnetSessionData() []TelnetSessionState {
+	if !ws.resolveTelnetService() {
+		return []TelnetSessionState{}
+	}
+	return ws.telnetService.GetSessionStates()
+}
+
+// getSoundingData returns the latest fathometer soundings
+func (ws *WebSocketService) getSoundingData() map[string]SoundingData {
+	if !ws.resolveTelnetService() {
+		return map[string]SoundingData{}
+	}
+	return ws.telnetService.GetLatestSoundings()
+}
+
+// resolveTelnetService looks up the telnet service once it is registered
+func (ws *WebSocketService) resolveTelnetService() bool {
+	if ws.telnetService != nil {
+		return true
+	}
+
+	instance, err := facades.App().Make("telnet_service")
+	if err != nil {
+		return false
+	}
+	telnetService, ok := instance.(*TelnetService)
+	if !ok {
+		return false
+	}
+	ws.telnetService = telnetService
+	return true
+}
+
 // getNavigationData collects vessel navigation data
 func (ws *WebSocketService) getNavigationData() map[string]NavigationData {
 	// Check and update cache if needed
@@ -352,6 +428,40 @@ func (ws *WebSocketService) getSensorData() map[string]SensorData {
 	return sensorData
 }
 
+// getAISContactData collects the AIS targets seen recently
+func (ws *WebSocketService) getAISContactData() map[string]AisContactData {
+	contactData := make(map[string]AisContactData)
+
+	for _, contact := range ws.aisService.GetActiveContacts() {
+		// Static data can arrive before the first position report
+		if contact.Latitude == 0 && contact.Longitude == 0 {
+			continue
+		}
+
+		contactData[strconv.FormatUint(uint64(contact.MMSI), 10)] = AisContactData{
+			MMSI:             contact.MMSI,
+			Name:             contact.Name,
+			CallSign:         contact.CallSign,
+			ShipType:         contact.ShipType,
+			AisClass:         contact.AisClass,
+			Destination:      contact.Destination,
+			LatitudeDecimal:  contact.Latitude,
+			LongitudeDecimal: contact.Longitude,
+			SpeedOverGround:  contact.SpeedOverGround,
+			CourseOverGround: contact.CourseOverGround,
+			TrueHeading:      contact.TrueHeading,
+			NavigationStatus: contact.NavigationStatus,
+			LengthM:          contact.DimensionToBow + contact.DimensionToStern,
+			WidthM:           contact.DimensionToPort + contact.DimensionToStbd,
+			OwnShip:          contact.OwnShip,
+			ReceivedBy:       contact.ReceivedBy,
+			LastUpdate:       contact.LastSeenAt,
+		}
+	}
+
+	return contactData
+}
+
 // getLastSensorRecord retrieves the most recent record for a sensor
 func getLastSensorRecord(sensorID string) *models.SensorRecord {
 	var lastRecord models.SensorRecord
@@ -416,12 +526,21 @@ func createTelemetryData(buffer *NMEABuffer, vessel *models.Kapal, callSign stri
 		LatitudeDecimal:     latDec,
 		LongitudeDecimal:    lonDec,
 		HeadingDegree:       buffer.HeadingDegree,
+		RawHeadingDegree:    buffer.RawHeadingDegree,
+		HeadingSource:       buffer.HeadingSource,
+		PositionSource:      buffer.PositionSource,
+		Estimated:           buffer.Estimated,
+		HeadingCalibration:  buffer.HeadingCalibration,
 		SpeedInKnots:        buffer.SpeedInKnots,
 		SpeedInKmh:          buffer.SpeedInKnots * 1.852,
+		CourseOverGround:    buffer.CourseOverGround,
 		GpsQualityIndicator: string(buffer.GpsQualityIndicator),
 		WaterDepth:          buffer.WaterDepth,
 		TelnetStatus:        getStatus(isActive),
-		LastUpdate:          buffer.LastGGATime,
+		LastUpdate:          buffer.LastPositionTime,
+		FixTime:             fixTimePtr(buffer.PositionFixTime),
+		ClockSkewMs:         buffer.ClockSkew.Milliseconds(),
+		Environment:         buffer.environmentSnapshot(),
 		CurrentKnotPerLiterGasoline: models.CalculateFuelEfficiency(
 			buffer.SpeedInKnots,
 			vessel.MinimumKnotPerLiterGasoline,
@@ -452,12 +571,19 @@ func createTelemetryDataFromRecord(record *models.VesselRecord, vessel *models.K
 		LatitudeDecimal:     latDec,
 		LongitudeDecimal:    lonDec,
 		HeadingDegree:       record.HeadingDegree,
+		RawHeadingDegree:    record.RawHeadingDegree,
+		HeadingSource:       record.HeadingSource,
+		PositionSource:      record.Source,
+		Estimated:           record.GpsQualityIndicator == models.InsDeadReckoning,
+		HeadingCalibration:  float64(vessel.Calibration),
 		SpeedInKnots:        record.SpeedInKnots,
 		SpeedInKmh:          record.SpeedInKnots * 1.852,
 		GpsQualityIndicator: string(record.GpsQualityIndicator),
 		WaterDepth:          record.WaterDepth,
 		TelnetStatus:        getStatus(isActive),
 		LastUpdate:          record.CreatedAt,
+		FixTime:             record.FixTime,
+		ClockSkewMs:         recordClockSkew(record).Milliseconds(),
 		CurrentKnotPerLiterGasoline: models.CalculateFuelEfficiency(
 			record.SpeedInKnots,
 			vessel.MinimumKnotPerLiterGasoline,
@@ -475,6 +601,22 @@ func createTelemetryDataFromRecord(record *models.VesselRecord, vessel *models.K
 	}
 }
 
+// fixTimePtr returns nil for a missing fix time so it is sent as null
+func fixTimePtr(fixTime time.Time) *time.Time {
+	if fixTime.IsZero() {
+		return nil
+	}
+	return &fixTime
+}
+
+// recordClockSkew returns how long after the receiver's fix a record's position arrived
+func recordClockSkew(record *models.VesselRecord) time.Duration {
+	if record.FixTime == nil || record.ReceivedAt == nil {
+		return 0
+	}
+	return record.ReceivedAt.Sub(*record.FixTime)
+}
+
 // getLastRecord retrieves the most recent record for a vessel
 func getLastRecord(callSign string) *models.VesselRecord {
 	var lastRecord models.VesselRecord
@@ -20,6 +20,7 @@ import (
 	"github.com/goravel/framework/schedule"
 	"github.com/goravel/framework/session"
 	"github.com/goravel/framework/support/carbon"
+	"github.com/goravel/framework/support/path"
 	"github.com/goravel/framework/testing"
 	"github.com/goravel/framework/translation"
 	"github.com/goravel/framework/validation"
@@ -146,10 +147,136 @@ func init() {
 		"navigation": map[string]any{
 			"host": config.Env("TCP_SERVER_NAVIGATION_HOST", "0.0.0.0"),
 			"port": config.Env("TCP_SERVER_NAVIGATION_PORT", "8080"),
+			// Longer lines are dropped whole; NMEA itself allows 82 characters
+			"max_line_length": config.Env("TCP_SERVER_NAVIGATION_MAX_LINE_LENGTH", 1024),
+			// Only accept data after a CALLSIGN,TOKEN handshake with the vessel's device secret
+			"require_auth": config.Env("TCP_SERVER_NAVIGATION_REQUIRE_AUTH", false),
+			// Reply to each line with none, echo or ack (ACK,<seq>,OK / ACK,<seq>,REJECT,<reason>);
+			// a vessel's ack_mode overrides this
+			"ack_mode": config.Env("TCP_SERVER_NAVIGATION_ACK_MODE", "echo"),
+			// UDP port for multiplexers that broadcast NMEA, leave empty to disable; never opened with require_auth
+			"udp_port": config.Env("TCP_SERVER_NAVIGATION_UDP_PORT", ""),
+			// TLS for vessels on public links; with a client CA the certificate CN must be the call sign
+			"tls": map[string]any{
+				"enabled":        config.Env("TCP_SERVER_NAVIGATION_TLS_ENABLED", false),
+				"cert_file":      config.Env("TCP_SERVER_NAVIGATION_TLS_CERT", ""),
+				"key_file":       config.Env("TCP_SERVER_NAVIGATION_TLS_KEY", ""),
+				"client_ca_file": config.Env("TCP_SERVER_NAVIGATION_TLS_CLIENT_CA", ""),
+			},
 		},
 		"sensor": map[string]any{
 			"host": config.Env("TCP_SERVER_SENSOR_HOST", "0.0.0.0"),
 			"port": config.Env("TCP_SERVER_SENSOR_PORT", "8085"),
+			// With a client CA the certificate CN must be the sensor ID
+			"tls": map[string]any{
+				"enabled":        config.Env("TCP_SERVER_SENSOR_TLS_ENABLED", false),
+				"cert_file":      config.Env("TCP_SERVER_SENSOR_TLS_CERT", ""),
+				"key_file":       config.Env("TCP_SERVER_SENSOR_TLS_KEY", ""),
+				"client_ca_file": config.Env("TCP_SERVER_SENSOR_TLS_CLIENT_CA", ""),
+			},
+		},
+		"telnet": map[string]any{
+			// Consecutive failed dials before a session is disabled until it is edited, 0 retries forever
+			"max_failures": config.Env("TELNET_MAX_FAILURES", 0),
+			// Seconds between stored soundings of a fathometer session; the live feed gets every reading
+			"sounding_interval": config.Env("TELNET_SOUNDING_INTERVAL", 1),
+		},
+		"polling": map[string]any{
+			// Dial the device behind every IPKapal entry and read its sentence family
+			"enabled": config.Env("IP_POLLING_ENABLED", false),
+			// Seconds without data before a connection is redialled, 0 waits forever
+			"idle_timeout": config.Env("IP_POLLING_IDLE_TIMEOUT", 60),
+		},
+		"failover": map[string]any{
+			// Seconds without data before the next source of a vessel takes over a role
+			"stale_after": config.Env("SOURCE_STALE_AFTER", 10),
+			// Seconds a higher priority source must be live again before it takes the role back
+			"failback_after": config.Env("SOURCE_FAILBACK_AFTER", 30),
+		},
+		"plausibility": map[string]any{
+			// Drop fixes the receiver marks invalid and 0,0 fallback positions
+			"reject_invalid": config.Env("PLAUSIBILITY_REJECT_INVALID", true),
+			// Fastest speed implied between two fixes for vessels without max_speed_knots, 0 disables
+			"max_speed_knots": config.Env("PLAUSIBILITY_MAX_SPEED_KNOTS", 60),
+			// Consecutive speed rejections after which the next fix becomes the new reference
+			"reanchor_after": config.Env("PLAUSIBILITY_REANCHOR_AFTER", 10),
+			// GeoJSON file of land polygons, positions inside them are rejected; empty disables
+			"land_file": config.Env("PLAUSIBILITY_LAND_FILE", ""),
+			// Store rejected fixes in rejected_fixes for audit
+			"keep_rejected": config.Env("PLAUSIBILITY_KEEP_REJECTED", false),
+		},
+		"dead_reckoning": map[string]any{
+			// Project the position from heading and speed while position sentences are missing
+			"enabled": config.Env("DEAD_RECKONING_ENABLED", false),
+			// Seconds without a position before estimates start
+			"start_after": config.Env("DEAD_RECKONING_START_AFTER", 5),
+			// Seconds after the last fix that estimates are made, after which the vessel freezes and disconnects
+			"max_duration": config.Env("DEAD_RECKONING_MAX_DURATION", 300),
+			// Record estimates with GPS quality "INS Dead reckoning"
+			"record": config.Env("DEAD_RECKONING_RECORD", false),
+		},
+		"recording": map[string]any{
+			// interval records every history_per_second seconds, adaptive only when a threshold below is crossed
+			"mode": config.Env("RECORD_MODE", "interval"),
+			// Adaptive defaults for vessels without their own; 0 disables a threshold
+			"distance_meters": config.Env("RECORD_DEADBAND_METERS", 25),
+			"course_degrees":  config.Env("RECORD_DEADBAND_DEGREES", 10),
+			"speed_knots":     config.Env("RECORD_DEADBAND_KNOTS", 1),
+			// Seconds after which a record is stored even without change
+			"max_interval": config.Env("RECORD_MAX_INTERVAL", 300),
+		},
+		"metrics": map[string]any{
+			// Serve ingest counters and gauges on /metrics in the Prometheus text format
+			"enabled": config.Env("METRICS_ENABLED", true),
+		},
+		"voyages": map[string]any{
+			// Split vessel records into voyages between stationary periods
+			"enabled": config.Env("VOYAGES_ENABLED", true),
+			// Speed in knots from which a vessel counts as making way
+			"moving_knots": config.Env("VOYAGES_MOVING_KNOTS", 1),
+			// Seconds of making way before a voyage starts
+			"depart_after": config.Env("VOYAGES_DEPART_AFTER", 120),
+			// Seconds stationary before a voyage ends on arrival
+			"arrive_after": config.Env("VOYAGES_ARRIVE_AFTER", 900),
+			// Seconds without a record before a voyage ends on a data gap
+			"gap_after": config.Env("VOYAGES_GAP_AFTER", 3600),
+		},
+		"write_behind": map[string]any{
+			// Queue vessel, telemetry and sensor records and insert them in batches
+			"enabled": config.Env("WRITE_BEHIND_ENABLED", true),
+			// Records per insert statement
+			"batch_size": config.Env("WRITE_BEHIND_BATCH_SIZE", 500),
+			// Records a table may queue before producers wait for a flush
+			"capacity": config.Env("WRITE_BEHIND_CAPACITY", 10000),
+			// Milliseconds between flushes of a queue that has not filled a batch
+			"flush_interval_ms": config.Env("WRITE_BEHIND_FLUSH_INTERVAL_MS", 1000),
+			// Further attempts for a batch the database refused before it is dropped
+			"max_retries": config.Env("WRITE_BEHIND_MAX_RETRIES", 5),
+			// Milliseconds before the first retry of a refused batch, doubled for every further one
+			"retry_backoff_ms": config.Env("WRITE_BEHIND_RETRY_BACKOFF_MS", 1000),
+		},
+		"nmea": map[string]any{
+			// Reject sentences without a *hh checksum instead of accepting them unchecked
+			"require_checksum": config.Env("NMEA_REQUIRE_CHECKSUM", false),
+			// Accept well-formed talker IDs that the parser does not know about
+			"allow_unknown_talkers": config.Env("NMEA_ALLOW_UNKNOWN_TALKERS", false),
+			// Which position sentence wins when a vessel sends both GGA and RMC: gga, rmc or latest
+			"position_precedence": config.Env("NMEA_POSITION_PRECEDENCE", "gga"),
+		},
+		"archive": map[string]any{
+			// Keep every raw line from vessels in gzip files per vessel per day
+			"enabled": config.Env("NMEA_ARCHIVE_ENABLED", false),
+			"path":    config.Env("NMEA_ARCHIVE_PATH", path.Storage("app/nmea")),
+			// Day files older than this are deleted, 0 keeps them forever
+			"retention_days": config.Env("NMEA_ARCHIVE_RETENTION_DAYS", 90),
+		},
+		"signalk": map[string]any{
+			// Publish the fleet state as Signal K deltas on /api/signalk/deltas
+			"output_enabled": config.Env("SIGNALK_OUTPUT_ENABLED", false),
+		},
+		"ais": map[string]any{
+			// Minutes after the last message before a contact drops off the live feed
+			"contact_timeout": config.Env("AIS_CONTACT_TIMEOUT", 10),
 		},
 	})
 
@@ -22,6 +22,21 @@ func (kernel Kernel) Migrations() []schema.Migration {
 		&migrations.M20250205140356CreateSensorsTable{},
 		&migrations.M20250205140421CreateSensorRecordsTable{},
 		&migrations.M20250202155431CreateGeolayersTable{},
+		&migrations.M20250301090000CreateAisContactsTable{},
+		&migrations.M20250302090000AddRawHeadingToVesselRecordsTable{},
+		&migrations.M20250303090000AddFixTimeToVesselRecordsTable{},
+		&migrations.M20250304090000CreateVesselTelemetryRecordsTable{},
+		&migrations.M20250305090000AddDeviceSecretsToKapalsTable{},
+		&migrations.M20250306090000CreateSignalkSourcesTable{},
+		&migrations.M20250307090000AddAckModeToKapalsTable{},
+		&migrations.M20250308090000CreateSoundingsTable{},
+		&migrations.M20250309090000AddSourcePriorityToKapalsTable{},
+		&migrations.M20250309090100AddSourceToVesselRecordsTable{},
+		&migrations.M20250310090000CreateRejectedFixesTable{},
+		&migrations.M20250310090100AddMaxSpeedToKapalsTable{},
+		&migrations.M20250311090000AddRecordingThresholdsToKapalsTable{},
+		&migrations.M20250312090000CreateVoyagesTable{},
+		&migrations.M20250312090100AddVoyageIDToVesselRecordsTable{},
 	}
 }
 
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250301090000CreateAisContactsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250301090000CreateAisContactsTable) Signature() string {
+	return "20250301090000_create_ais_contacts_table"
+}
+
+// Up Run the migrations.
+func (r *M20250301090000CreateAisContactsTable) Up() error {
+	if !facades.Schema().HasTable("ais_contacts") {
+		return facades.Schema().Create("ais_contacts", func(table schema.Blueprint) {
+			// Primary Key
+			table.UnsignedInteger("mmsi")
+			table.Primary("mmsi")
+
+			// Static data
+			table.String("name", 20).Nullable()
+			table.String("call_sign", 7).Nullable()
+			table.UnsignedInteger("imo").Default(0)
+			table.Integer("ship_type").Default(0)
+			table.Enum("ais_class", []any{"A", "B"}).Default("A")
+			table.String("destination", 20).Nullable()
+			table.Double("draught").Default(0)
+			table.Integer("dimension_to_bow").Default(0)
+			table.Integer("dimension_to_stern").Default(0)
+			table.Integer("dimension_to_port").Default(0)
+			table.Integer("dimension_to_starboard").Default(0)
+
+			// Dynamic data
+			table.Double("latitude").Default(0)
+			table.Double("longitude").Default(0)
+			table.Double("speed_over_ground").Default(0)
+			table.Double("course_over_ground").Default(0)
+			table.Integer("true_heading").Nullable()
+			table.Integer("navigation_status").Default(15)
+
+			// Reception
+			table.Boolean("own_ship").Default(false)
+			table.String("received_by", 255).Nullable()
+			table.Integer("last_message_type").Default(0)
+			table.DateTime("last_seen_at")
+
+			// Timestamps
+			table.Timestamps()
+
+			table.Index("last_seen_at")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250301090000CreateAisContactsTable) Down() error {
+	return facades.Schema().DropIfExists("ais_contacts")
+}
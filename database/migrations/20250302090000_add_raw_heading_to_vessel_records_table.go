@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250302090000AddRawHeadingToVesselRecordsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250302090000AddRawHeadingToVesselRecordsTable) Signature() string {
+	return "20250302090000_add_raw_heading_to_vessel_records_table"
+}
+
+// Up Run the migrations.
+func (r *M20250302090000AddRawHeadingToVesselRecordsTable) Up() error {
+	if !facades.Schema().HasColumn("vessel_records", "raw_heading_degree") {
+		return facades.Schema().Table("vessel_records", func(table schema.Blueprint) {
+			// Heading as received, before magnetic and calibration corrections
+			table.Double("raw_heading_degree").Default(0)
+			table.String("heading_source", 3).Nullable()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250302090000AddRawHeadingToVesselRecordsTable) Down() error {
+	return facades.Schema().DropColumns("vessel_records", []string{"raw_heading_degree", "heading_source"})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250303090000AddFixTimeToVesselRecordsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250303090000AddFixTimeToVesselRecordsTable) Signature() string {
+	return "20250303090000_add_fix_time_to_vessel_records_table"
+}
+
+// Up Run the migrations.
+func (r *M20250303090000AddFixTimeToVesselRecordsTable) Up() error {
+	if !facades.Schema().HasColumn("vessel_records", "fix_time") {
+		return facades.Schema().Table("vessel_records", func(table schema.Blueprint) {
+			// UTC time reported by the receiver and the time the sentence reached the server
+			table.DateTime("fix_time", 3).Nullable()
+			table.DateTime("received_at", 3).Nullable()
+			table.Index("call_sign", "fix_time")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250303090000AddFixTimeToVesselRecordsTable) Down() error {
+	return facades.Schema().Table("vessel_records", func(table schema.Blueprint) {
+		table.DropIndex("call_sign", "fix_time")
+		table.DropColumn("fix_time", "received_at")
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250304090000CreateVesselTelemetryRecordsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250304090000CreateVesselTelemetryRecordsTable) Signature() string {
+	return "20250304090000_create_vessel_telemetry_records_table"
+}
+
+// Up Run the migrations.
+func (r *M20250304090000CreateVesselTelemetryRecordsTable) Up() error {
+	if !facades.Schema().HasTable("vessel_telemetry_records") {
+		return facades.Schema().Create("vessel_telemetry_records", func(table schema.Blueprint) {
+			table.ID()
+			table.String("call_sign", 50)
+			table.String("name", 50)
+			table.Double("value")
+			table.String("unit", 10).Nullable()
+			table.String("source", 3)
+			table.Timestamps()
+
+			table.Index("call_sign", "name", "created_at")
+			table.Foreign("call_sign").References("call_sign").On("kapals").CascadeOnUpdate().CascadeOnDelete()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250304090000CreateVesselTelemetryRecordsTable) Down() error {
+	return facades.Schema().DropIfExists("vessel_telemetry_records")
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250305090000AddDeviceSecretsToKapalsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250305090000AddDeviceSecretsToKapalsTable) Signature() string {
+	return "20250305090000_add_device_secrets_to_kapals_table"
+}
+
+// Up Run the migrations.
+func (r *M20250305090000AddDeviceSecretsToKapalsTable) Up() error {
+	if !facades.Schema().HasColumn("kapals", "device_secret_hash") {
+		if err := facades.Schema().Table("kapals", func(table schema.Blueprint) {
+			// Only the SHA-256 hash of the secret is kept
+			table.String("device_secret_hash", 64).Nullable()
+			table.DateTime("secret_rotated_at").Nullable()
+		}); err != nil {
+			return err
+		}
+	}
+
+	// ip_kapals is optional, it is not created on every installation
+	if facades.Schema().HasTable("ip_kapals") && !facades.Schema().HasColumn("ip_kapals", "token_hash") {
+		return facades.Schema().Table("ip_kapals", func(table schema.Blueprint) {
+			table.String("token_hash", 64).Nullable()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250305090000AddDeviceSecretsToKapalsTable) Down() error {
+	if facades.Schema().HasTable("ip_kapals") {
+		if err := facades.Schema().DropColumns("ip_kapals", []string{"token_hash"}); err != nil {
+			return err
+		}
+	}
+	return facades.Schema().DropColumns("kapals", []string{"device_secret_hash", "secret_rotated_at"})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250306090000CreateSignalkSourcesTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250306090000CreateSignalkSourcesTable) Signature() string {
+	return "20250306090000_create_signalk_sources_table"
+}
+
+// Up Run the migrations.
+func (r *M20250306090000CreateSignalkSourcesTable) Up() error {
+	if !facades.Schema().HasTable("signalk_sources") {
+		return facades.Schema().Create("signalk_sources", func(table schema.Blueprint) {
+			table.ID()
+			table.String("call_sign", 50)
+			table.String("url", 255)
+			table.Boolean("enabled").Default(true)
+			table.Timestamps()
+
+			table.Index("call_sign")
+			table.Foreign("call_sign").References("call_sign").On("kapals").CascadeOnUpdate().CascadeOnDelete()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250306090000CreateSignalkSourcesTable) Down() error {
+	return facades.Schema().DropIfExists("signalk_sources")
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250307090000AddAckModeToKapalsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250307090000AddAckModeToKapalsTable) Signature() string {
+	return "20250307090000_add_ack_mode_to_kapals_table"
+}
+
+// Up Run the migrations.
+func (r *M20250307090000AddAckModeToKapalsTable) Up() error {
+	if facades.Schema().HasColumn("kapals", "ack_mode") {
+		return nil
+	}
+
+	return facades.Schema().Table("kapals", func(table schema.Blueprint) {
+		// NULL uses the navigation listener's ack mode
+		table.String("ack_mode", 10).Nullable()
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250307090000AddAckModeToKapalsTable) Down() error {
+	return facades.Schema().DropColumns("kapals", []string{"ack_mode"})
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250308090000CreateSoundingsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250308090000CreateSoundingsTable) Signature() string {
+	return "20250308090000_create_soundings_table"
+}
+
+// Up Run the migrations.
+func (r *M20250308090000CreateSoundingsTable) Up() error {
+	if !facades.Schema().HasTable("soundings") {
+		return facades.Schema().Create("soundings", func(table schema.Blueprint) {
+			table.ID()
+			table.UnsignedBigInteger("telnet_session_id")
+			table.Double("latitude").Nullable()
+			table.Double("longitude").Nullable()
+			table.Double("depth_meters")
+			table.Double("offset_meters").Default(0)
+			table.String("source", 5)
+			table.Text("raw_data").Nullable()
+			table.DateTime("measured_at", 3)
+			table.Timestamps()
+
+			table.Index("telnet_session_id", "measured_at")
+			table.Foreign("telnet_session_id").References("id").On("telnet_sessions").CascadeOnDelete()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250308090000CreateSoundingsTable) Down() error {
+	return facades.Schema().DropIfExists("soundings")
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250309090000AddSourcePriorityToKapalsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250309090000AddSourcePriorityToKapalsTable) Signature() string {
+	return "20250309090000_add_source_priority_to_kapals_table"
+}
+
+// Up Run the migrations.
+func (r *M20250309090000AddSourcePriorityToKapalsTable) Up() error {
+	if facades.Schema().HasColumn("kapals", "source_priority") {
+		return nil
+	}
+
+	return facades.Schema().Table("kapals", func(table schema.Blueprint) {
+		// Comma separated sources, most preferred first, e.g. "telnet:3,tcp"
+		table.String("source_priority").Nullable()
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250309090000AddSourcePriorityToKapalsTable) Down() error {
+	return facades.Schema().DropColumns("kapals", []string{"source_priority"})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250309090100AddSourceToVesselRecordsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250309090100AddSourceToVesselRecordsTable) Signature() string {
+	return "20250309090100_add_source_to_vessel_records_table"
+}
+
+// Up Run the migrations.
+func (r *M20250309090100AddSourceToVesselRecordsTable) Up() error {
+	if facades.Schema().HasColumn("vessel_records", "source") {
+		return nil
+	}
+
+	return facades.Schema().Table("vessel_records", func(table schema.Blueprint) {
+		// Pipeline source of the position, e.g. "tcp", "udp" or "telnet:3"
+		table.String("source", 32).Nullable()
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250309090100AddSourceToVesselRecordsTable) Down() error {
+	return facades.Schema().DropColumns("vessel_records", []string{"source"})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250310090000CreateRejectedFixesTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250310090000CreateRejectedFixesTable) Signature() string {
+	return "20250310090000_create_rejected_fixes_table"
+}
+
+// Up Run the migrations.
+func (r *M20250310090000CreateRejectedFixesTable) Up() error {
+	if !facades.Schema().HasTable("rejected_fixes") {
+		return facades.Schema().Create("rejected_fixes", func(table schema.Blueprint) {
+			table.ID()
+			table.String("call_sign")
+			table.String("source", 32).Nullable()
+			table.Double("latitude")
+			table.Double("longitude")
+			table.String("reason", 10)
+			table.Double("implied_speed_knots").Nullable()
+			table.DateTime("fix_time", 3).Nullable()
+			table.DateTime("received_at", 3)
+			table.Timestamps()
+
+			table.Index("call_sign", "received_at")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250310090000CreateRejectedFixesTable) Down() error {
+	return facades.Schema().DropIfExists("rejected_fixes")
+}
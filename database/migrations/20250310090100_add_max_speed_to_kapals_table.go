@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250310090100AddMaxSpeedToKapalsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250310090100AddMaxSpeedToKapalsTable) Signature() string {
+	return "20250310090100_add_max_speed_to_kapals_table"
+}
+
+// Up Run the migrations.
+func (r *M20250310090100AddMaxSpeedToKapalsTable) Up() error {
+	if facades.Schema().HasColumn("kapals", "max_speed_knots") {
+		return nil
+	}
+
+	return facades.Schema().Table("kapals", func(table schema.Blueprint) {
+		// 0 uses the plausibility filter default
+		table.Double("max_speed_knots").Default(0)
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250310090100AddMaxSpeedToKapalsTable) Down() error {
+	return facades.Schema().DropColumns("kapals", []string{"max_speed_knots"})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250311090000AddRecordingThresholdsToKapalsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250311090000AddRecordingThresholdsToKapalsTable) Signature() string {
+	return "20250311090000_add_recording_thresholds_to_kapals_table"
+}
+
+// Up Run the migrations.
+func (r *M20250311090000AddRecordingThresholdsToKapalsTable) Up() error {
+	if facades.Schema().HasColumn("kapals", "record_mode") {
+		return nil
+	}
+
+	return facades.Schema().Table("kapals", func(table schema.Blueprint) {
+		// NULL and 0 use the tcp.recording defaults
+		table.String("record_mode", 10).Nullable()
+		table.Double("deadband_meters").Default(0)
+		table.Double("deadband_degrees").Default(0)
+		table.Double("deadband_knots").Default(0)
+		table.Integer("max_record_interval").Default(0)
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250311090000AddRecordingThresholdsToKapalsTable) Down() error {
+	return facades.Schema().DropColumns("kapals", []string{"record_mode", "deadband_meters", "deadband_degrees", "deadband_knots", "max_record_interval"})
+}
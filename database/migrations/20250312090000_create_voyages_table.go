@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250312090000CreateVoyagesTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250312090000CreateVoyagesTable) Signature() string {
+	return "20250312090000_create_voyages_table"
+}
+
+// Up Run the migrations.
+func (r *M20250312090000CreateVoyagesTable) Up() error {
+	if !facades.Schema().HasTable("voyages") {
+		return facades.Schema().Create("voyages", func(table schema.Blueprint) {
+			table.ID()
+			table.String("call_sign")
+			table.String("status", 10)
+			table.String("end_reason", 10).Nullable()
+			table.DateTime("started_at", 3)
+			table.Double("start_latitude")
+			table.Double("start_longitude")
+			table.DateTime("ended_at", 3).Nullable()
+			table.Double("end_latitude")
+			table.Double("end_longitude")
+			table.DateTime("last_record_at", 3)
+			table.Double("distance_meters").Default(0)
+			table.Double("max_speed_knots").Default(0)
+			table.Double("avg_speed_knots").Default(0)
+			table.Timestamps()
+
+			table.Index("call_sign", "started_at")
+			table.Index("call_sign", "status")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20250312090000CreateVoyagesTable) Down() error {
+	return facades.Schema().DropIfExists("voyages")
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20250312090100AddVoyageIDToVesselRecordsTable struct {
+}
+
+// Signature The unique signature for the migration.
+func (r *M20250312090100AddVoyageIDToVesselRecordsTable) Signature() string {
+	return "20250312090100_add_voyage_id_to_vessel_records_table"
+}
+
+// Up Run the migrations.
+func (r *M20250312090100AddVoyageIDToVesselRecordsTable) Up() error {
+	if facades.Schema().HasColumn("vessel_records", "voyage_id") {
+		return nil
+	}
+
+	return facades.Schema().Table("vessel_records", func(table schema.Blueprint) {
+		// Voyage the record belongs to, null while the vessel is stationary
+		table.UnsignedBigInteger("voyage_id").Nullable()
+		table.Index("voyage_id")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20250312090100AddVoyageIDToVesselRecordsTable) Down() error {
+	return facades.Schema().DropColumns("vessel_records", []string{"voyage_id"})
+}
@@ -8,12 +8,12 @@ require (
 	github.com/gin-gonic/gin v1.10.0
 	github.com/goravel/framework v1.15.3
 	github.com/goravel/gin v1.3.2
+	github.com/mattn/go-sqlite3 v1.14.24
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/grpc v1.70.0
 )
 
 require (
-	github.com/mattn/go-sqlite3 v1.14.24 // indirect
 	github.com/maurice2k/ultrapool v1.1.1 // indirect
 	github.com/paulmach/orb v0.11.1 // indirect
 	github.com/twpayne/go-geom v1.6.0 // indirect
@@ -77,7 +77,7 @@ require (
 	github.com/go-viper/mapstructure/v2 v2.2.1 // indirect
 	github.com/goccy/go-json v0.10.4 // indirect
 	github.com/golang-jwt/jwt/v4 v4.5.1 // indirect
-	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/golang-migrate/migrate/v4 v4.18.1 // indirect
 	github.com/golang-sql/civil v0.0.0-20220223132316-b832511892a9 // indirect
 	github.com/golang-sql/sqlexp v0.1.0 // indirect
@@ -173,7 +173,7 @@ require (
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/arch v0.12.0 // indirect
-	golang.org/x/crypto v0.32.0 // indirect
+	golang.org/x/crypto v0.32.0
 	golang.org/x/exp v0.0.0-20250106191152-7588d65b2ba8 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/oauth2 v0.24.0 // indirect
@@ -8,6 +8,7 @@ import (
 	"github.com/goravel/framework/facades"
 
 	"goravel/app/http/controllers"
+	"goravel/app/http/middleware"
 )
 
 func Api() {
@@ -16,6 +17,11 @@ func Api() {
 
 	kapalController := controllers.NewKapalController()
 	sensorController := controllers.NewSensorController()
+	aisContactController := controllers.NewAisContactController()
+	soundingController := controllers.NewSoundingController()
+	navigationController := controllers.NewNavigationController()
+	signalKController := controllers.NewSignalKController()
+	voyageController := controllers.NewVoyageController()
 
 
 	// Geolayer controller
@@ -50,6 +56,8 @@ func Api() {
 			kapal.Get("/", kapalController.Index)
 			kapal.Get("/view", kapalController.View)
 			kapal.Get("/{call_sign}", kapalController.Show)
+			kapal.Get("/{call_sign}/voyages", voyageController.Index)
+			kapal.Get("/{call_sign}/voyages/{id}", voyageController.Show)
 
 			// Protected routes - add auth middleware
 			// kapal.Middleware(middleware.Authenticate{}).Group(func(auth http.Router) {
@@ -58,6 +66,13 @@ func Api() {
 			kapal.Delete("/{call_sign}", kapalController.Destroy)
 			kapal.Put("/{call_sign}/restore", kapalController.Restore) // Route to restore soft-deleted vessels
 			// })
+
+			// Device credentials always require a signed-in user, a secret issued to anyone would pass the handshake
+			kapal.Middleware(middleware.Auth()).Group(func(auth route.Router) {
+				auth.Post("/{call_sign}/secret", kapalController.RotateSecret) // Issue a new device secret for the TCP handshake
+				auth.Delete("/{call_sign}/secret", kapalController.RevokeSecret)
+				auth.Post("/{call_sign}/ip/{id}/token", kapalController.RotateIPToken)
+			})
 		})
 
 		// Sensor routes
@@ -83,6 +98,41 @@ func Api() {
 			sensor.Get("/history", sensorController.GetHistorySensorStream)
 		})
 
+		// AIS contact routes
+		router.Prefix("ais").Group(func(ais route.Router) {
+			ais.Get("/", aisContactController.Index)
+			ais.Get("/{mmsi}", aisContactController.Show)
+		})
+
+		// Fathometer sounding routes
+		router.Prefix("soundings").Group(func(soundings route.Router) {
+			soundings.Get("/", soundingController.Index)
+		})
+
+		// Navigation listener status routes
+		router.Prefix("navigation").Group(func(navigation route.Router) {
+			navigation.Get("/udp/sources", navigationController.UDPSources)
+			navigation.Get("/ip/connections", navigationController.IPConnections)
+			navigation.Get("/telnet/sessions", navigationController.TelnetSessions)
+			navigation.Get("/sources", navigationController.Sources) // Selected source per vessel role
+			navigation.Get("/plausibility", navigationController.Plausibility)
+			navigation.Get("/rejected_fixes", navigationController.RejectedFixes)
+			navigation.Get("/write_queue", navigationController.WriteQueue) // Depth and flush latency of the record queues
+			navigation.Get("/nmea/{call_sign}", navigationController.RawLog) // Raw sentence archive for a time window
+		})
+
+		// Signal K routes
+		router.Prefix("signalk").Group(func(signalK route.Router) {
+			signalK.Get("/sources", signalKController.Sources)
+			signalK.Get("/deltas", signalKController.Fleet) // Fleet state as Signal K deltas, when enabled
+
+			// The server dials a registered source and takes positions from it, so only a signed-in user may add one
+			signalK.Middleware(middleware.Auth()).Group(func(auth route.Router) {
+				auth.Post("/sources", signalKController.StoreSource)
+				auth.Delete("/sources/{id}", signalKController.DestroySource)
+			})
+		})
+
 		// router.Prefix("geolayer").Group(func(geolayer route.Router) {
 		// 	// geolayer.Get("/view", geolayerController.View)
 
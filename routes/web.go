@@ -26,4 +26,7 @@ func Web() {
 	
 	facades.Route().Get("/api/vessel/stream-history", (&controllers.VesselRecordController{}).StreamHistory)
 	facades.Route().Get("/api/sensors/history/stream", (&controllers.SensorController{}).GetHistorySensorStream)
+
+	// Prometheus scrape endpoint
+	facades.Route().Get("/metrics", controllers.NewMetricsController().Index)
 }